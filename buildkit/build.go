@@ -28,7 +28,7 @@ type BuildWithBuildkitClientOptions struct {
 	DumpLLB         bool
 	OutputDir       string
 	ProgressMode    string
-	SecretsHash     string
+	SecretHashes    map[string]string
 	Secrets         map[string]string
 	Platform        BuildPlatform
 	ImportCache     string
@@ -90,7 +90,7 @@ func BuildWithBuildkitClient(appDir string, plan *plan.BuildPlan, opts BuildWith
 
 	llbState, image, err := ConvertPlanToLLB(plan, ConvertPlanOptions{
 		BuildPlatform: buildPlatform,
-		SecretsHash:   opts.SecretsHash,
+		SecretHashes:  opts.SecretHashes,
 		CacheKey:      opts.CacheKey,
 	})
 	if err != nil {
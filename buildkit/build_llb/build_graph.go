@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/moby/buildkit/client/llb"
@@ -22,8 +23,7 @@ type BuildGraph struct {
 	Platform   *specs.Platform
 	LocalState *llb.State
 
-	secretsFile     *llb.State
-	usedSecretsBase *llb.State
+	secretHashes map[string]string
 }
 
 type BuildGraphOutput struct {
@@ -31,14 +31,7 @@ type BuildGraphOutput struct {
 	GraphEnv BuildEnvironment
 }
 
-func NewBuildGraph(plan *plan.BuildPlan, localState *llb.State, cacheStore *BuildKitCacheStore, secretsHash string, platform *specs.Platform) (*BuildGraph, error) {
-	var secretsFile *llb.State
-	if secretsHash != "" {
-		st := llb.Scratch().File(llb.Mkfile("/secrets-hash", 0644, []byte(secretsHash)), llb.WithCustomName("[railpack] secrets hash"))
-		secretsFile = &st
-	}
-	usedSecretsBase := llb.Image("alpine:latest", llb.WithCustomName("[railpack] loading secrets"))
-
+func NewBuildGraph(plan *plan.BuildPlan, localState *llb.State, cacheStore *BuildKitCacheStore, secretHashes map[string]string, platform *specs.Platform) (*BuildGraph, error) {
 	g := &BuildGraph{
 		graph:      graph.NewGraph(),
 		CacheStore: cacheStore,
@@ -46,8 +39,7 @@ func NewBuildGraph(plan *plan.BuildPlan, localState *llb.State, cacheStore *Buil
 		Platform:   platform,
 		LocalState: localState,
 
-		secretsFile:     secretsFile,
-		usedSecretsBase: &usedSecretsBase,
+		secretHashes: secretHashes,
 	}
 
 	// Create a node for each step
@@ -65,13 +57,8 @@ func NewBuildGraph(plan *plan.BuildPlan, localState *llb.State, cacheStore *Buil
 	// Add dependencies to each node
 	for _, node := range g.graph.GetNodes() {
 		llbNode := node.(*StepNode)
-		for _, input := range llbNode.Step.Inputs {
-			// This input does not reference another step
-			if input.Step == "" {
-				continue
-			}
-
-			if depNode, exists := g.graph.GetNode(input.Step); exists {
+		for _, depName := range stepDependencies(llbNode.Step) {
+			if depNode, exists := g.graph.GetNode(depName); exists {
 				// Create edges between the current node and the dependency node
 				parents := llbNode.GetParents()
 				parents = append(parents, depNode)
@@ -91,6 +78,33 @@ func NewBuildGraph(plan *plan.BuildPlan, localState *llb.State, cacheStore *Buil
 	return g, nil
 }
 
+// stepDependencies returns the names of the steps that a step uses as an input, mounts into a command, or copies from
+func stepDependencies(step *plan.Step) []string {
+	deps := []string{}
+	for _, input := range step.Inputs {
+		if input.Step != "" && !slices.Contains(deps, input.Step) {
+			deps = append(deps, input.Step)
+		}
+	}
+
+	for _, cmd := range step.Commands {
+		switch cmd := cmd.(type) {
+		case plan.ExecCommand:
+			for _, mount := range cmd.Mounts {
+				if mount.Step != "" && !slices.Contains(deps, mount.Step) {
+					deps = append(deps, mount.Step)
+				}
+			}
+		case plan.CopyCommand:
+			if cmd.Step != "" && !slices.Contains(deps, cmd.Step) {
+				deps = append(deps, cmd.Step)
+			}
+		}
+	}
+
+	return deps
+}
+
 // GenerateLLB generates the LLB state for the build graph
 func (g *BuildGraph) GenerateLLB() (*BuildGraphOutput, error) {
 	// Get processing order using topological sort
@@ -246,19 +260,16 @@ func (g *BuildGraph) convertExecCommandToLLB(node *StepNode, cmd plan.ExecComman
 		opts = append(opts, llb.WithCustomName(cmd.CustomName))
 	}
 
-	if len(node.Step.Secrets) > 0 {
-		// These options mount all secrets as environments variables
-		secretOpts := []llb.RunOption{}
-		for _, secret := range g.Plan.Secrets {
-			secretOpts = append(secretOpts, llb.AddSecret(secret, llb.SecretID(secret), llb.SecretAsEnv(true), llb.SecretAsEnvName(secret)))
-		}
+	secretOpts, err := g.getSecretOptions(node.Step)
+	if err != nil {
+		return state, err
+	}
+
+	if len(secretOpts) > 0 {
 		opts = append(opts, secretOpts...)
 
-		if g.secretsFile != nil {
-			// These options mount the secrets hash file to the FS so that we can invalidate the cache if the secrets change
-			secretInvalidationMountOpts := g.getSecretInvalidationMountOptions(node, secretOpts)
-			opts = append(opts, secretInvalidationMountOpts...)
-		}
+		// These options mount the hashes of the used secrets to the FS so that we can invalidate the cache if they change
+		opts = append(opts, g.getSecretInvalidationMountOptions(node.Step)...)
 	}
 
 	if len(node.Step.Caches) > 0 {
@@ -269,6 +280,26 @@ func (g *BuildGraph) convertExecCommandToLLB(node *StepNode, cmd plan.ExecComman
 		opts = append(opts, cacheOpts...)
 	}
 
+	if len(cmd.Mounts) > 0 {
+		mountOpts, err := g.getMountOptions(cmd.Mounts)
+		if err != nil {
+			return state, err
+		}
+		opts = append(opts, mountOpts...)
+	}
+
+	switch cmd.Network {
+	case "", plan.NetworkModeDefault:
+	case plan.NetworkModeNone:
+		opts = append(opts, llb.Network(llb.NetModeNone))
+	default:
+		return state, fmt.Errorf("unknown network mode %q. Must be one of: %s, %s", cmd.Network, plan.NetworkModeDefault, plan.NetworkModeNone)
+	}
+
+	if cmd.Workdir != "" {
+		opts = append(opts, llb.Dir(cmd.Workdir))
+	}
+
 	s := state.Run(opts...).Root()
 	return s, nil
 }
@@ -285,25 +316,53 @@ func (g *BuildGraph) convertPathCommandToLLB(node *StepNode, cmd plan.PathComman
 // convertCopyCommandToLLB converts a copy command to an LLB state
 func (g *BuildGraph) convertCopyCommandToLLB(cmd plan.CopyCommand, state llb.State) (llb.State, error) {
 	var src llb.State
-	if cmd.Image != "" {
+	srcPath := cmd.Src
+
+	switch {
+	case cmd.Image != "" && cmd.Step != "":
+		return state, fmt.Errorf("copy of %s cannot be from both an image and a step", cmd.Src)
+	case cmd.Image != "":
 		src = llb.Image(cmd.Image, llb.Platform(*g.Platform))
-	} else {
+	case cmd.Step != "":
+		node, exists := g.graph.GetNode(cmd.Step)
+		if !exists {
+			return state, fmt.Errorf("step %q to copy %s from not found", cmd.Step, cmd.Src)
+		}
+		src = g.GetStateForInput(plan.NewStepInput(node.GetName()))
+		srcPath, _ = resolvePaths(cmd.Src)
+	default:
 		src = *g.LocalState
 	}
 
+	copyInfo := &llb.CopyInfo{
+		CreateDestPath:      true,
+		FollowSymlinks:      cmd.ShouldFollowSymlinks(),
+		CopyDirContentsOnly: false,
+		AllowWildcard:       true,
+		AllowEmptyWildcard:  true,
+		ExcludePatterns:     cmd.Exclude,
+	}
+
+	if cmd.Chown != "" {
+		chown := llb.WithUser(cmd.Chown).(llb.ChownOpt)
+		copyInfo.ChownOpt = &chown
+	}
+
+	if cmd.Chmod != "" {
+		mode, err := strconv.ParseUint(cmd.Chmod, 8, 32)
+		if err != nil {
+			return state, fmt.Errorf("invalid chmod %q for copy of %s. Must be an octal mode (e.g. 0755)", cmd.Chmod, cmd.Src)
+		}
+		copyInfo.Mode = &llb.ChmodOpt{Mode: os.FileMode(mode)}
+	}
+
 	opts := []llb.ConstraintsOpt{}
 
 	if cmd.Src == cmd.Dest {
 		opts = append(opts, llb.WithCustomName(fmt.Sprintf("copy %s", cmd.Src)))
 	}
 
-	s := state.File(llb.Copy(src, cmd.Src, cmd.Dest, &llb.CopyInfo{
-		CreateDestPath:      true,
-		FollowSymlinks:      true,
-		CopyDirContentsOnly: false,
-		AllowWildcard:       true,
-		AllowEmptyWildcard:  true,
-	}), opts...)
+	s := state.File(llb.Copy(src, srcPath, cmd.Dest, copyInfo), opts...)
 
 	return s, nil
 }
@@ -336,41 +395,71 @@ func (g *BuildGraph) convertFileCommandToLLB(cmd plan.FileCommand, state llb.Sta
 	return s, nil
 }
 
-func (g *BuildGraph) getSecretInvalidationMountOptions(node *StepNode, secretOpts []llb.RunOption) []llb.RunOption {
+// getSecretOptions returns the options that mount the secrets a step has access to.
+// Secrets are mounted as environment variables, unless the step mounts them as files
+func (g *BuildGraph) getSecretOptions(step *plan.Step) ([]llb.RunOption, error) {
 	opts := []llb.RunOption{}
+	fileSecrets := step.SecretFileNames()
 
-	if len(node.Step.Secrets) == 0 || g.secretsFile == nil {
-		return opts
+	for _, secret := range g.Plan.Secrets {
+		if step.UsesSecret(secret) && !slices.Contains(fileSecrets, secret) {
+			opts = append(opts, secretEnvOption(secret))
+		}
 	}
 
-	// If all secrets are included, we can just copy the secrets hash file to the new state
-	if slices.Contains(node.Step.Secrets, "*") {
-		opts = append(opts, llb.AddMount("/secrets-hash", *g.secretsFile))
-	} else {
-		// If not all secrets are included, we want to compute the hash of only the used secrets
-		secrets := slices.Clone(node.Step.Secrets)
-		slices.Sort(secrets)
-		secretsString := "$" + strings.Join(secrets, " $")
+	for _, secretFile := range step.SecretFiles {
+		if !slices.Contains(g.Plan.Secrets, secretFile.Secret) {
+			return nil, fmt.Errorf("secret file %s in step %s references unknown secret %s", secretFile.Target, step.Name, secretFile.Secret)
+		}
 
-		// Hash all the secrets into a single file
-		hashCommand := fmt.Sprintf("sh -c 'echo \"%s\" | sha256sum > /used-secrets-hash'", secretsString)
+		var mode os.FileMode = 0400
+		if secretFile.Mode != 0 {
+			mode = secretFile.Mode
+		}
+
+		target, _ := resolvePaths(secretFile.Target)
+		opts = append(opts, llb.AddSecret(target, llb.SecretID(secretFile.Secret), llb.SecretFileOpt(0, 0, int(mode))))
+	}
+
+	return opts, nil
+}
+
+func secretEnvOption(secret string) llb.RunOption {
+	return llb.AddSecret(secret, llb.SecretID(secret), llb.SecretAsEnv(true), llb.SecretAsEnvName(secret))
+}
+
+// getUsedSecrets returns the secrets of the plan that a step uses as environment variables or files
+func (g *BuildGraph) getUsedSecrets(step *plan.Step) []string {
+	fileSecrets := step.SecretFileNames()
+	secrets := []string{}
+	for _, secret := range g.Plan.Secrets {
+		if step.UsesSecret(secret) || slices.Contains(fileSecrets, secret) {
+			secrets = append(secrets, secret)
+		}
+	}
 
-		usedSecretsState := g.usedSecretsBase.
-			File(llb.Copy(*g.secretsFile, "/secrets-hash", "/secrets-hash"),
-				llb.WithCustomName("[railpack] copy secrets hash")).
-			Run(append([]llb.RunOption{
-				llb.Shlex(hashCommand),
-				llb.WithCustomName("[railpack] hash used secrets")},
-				secretOpts...)...).Root()
+	slices.Sort(secrets)
+	return slices.Compact(secrets)
+}
 
-		usedSecretsHash := llb.Scratch().File(
-			llb.Copy(usedSecretsState, "/used-secrets-hash", "/used-secrets-hash"),
-			llb.WithCustomName("[railpack] copy used secrets hash"))
+// getSecretInvalidationMountOptions mounts a file with the hashes of the secrets that the step uses.
+// The file is part of the cache key of the command, so the layer is only rebuilt when one of these secrets changes
+func (g *BuildGraph) getSecretInvalidationMountOptions(step *plan.Step) []llb.RunOption {
+	lines := []string{}
+	for _, secret := range g.getUsedSecrets(step) {
+		if hash, ok := g.secretHashes[secret]; ok {
+			lines = append(lines, fmt.Sprintf("%s=%s", secret, hash))
+		}
+	}
 
-		opts = append(secretOpts, llb.AddMount("/used-secrets-hash", usedSecretsHash))
+	if len(lines) == 0 {
+		return []llb.RunOption{}
 	}
 
-	return opts
+	hashes := []byte(strings.Join(lines, "\n") + "\n")
+	st := llb.Scratch().File(llb.Mkfile("/secrets-hash", 0644, hashes), llb.WithCustomName("[railpack] secrets hash"))
+
+	return []llb.RunOption{llb.AddMount("/secrets-hash", st, llb.Readonly)}
 }
 
 // getCacheMountOptions returns the llb.RunOption slice for the given cache keys
@@ -394,3 +483,47 @@ func (g *BuildGraph) getCacheMountOptions(cacheKeys []string) ([]llb.RunOption,
 	}
 	return opts, nil
 }
+
+// getMountOptions returns the llb.RunOption slice for the bind and tmpfs mounts of an exec command
+func (g *BuildGraph) getMountOptions(mounts []plan.Mount) ([]llb.RunOption, error) {
+	var opts []llb.RunOption
+
+	for _, mount := range mounts {
+		if err := mount.Validate(); err != nil {
+			return nil, err
+		}
+
+		switch mount.Type {
+		case plan.MountTypeTmpfs:
+			tmpfsOpts := []llb.TmpfsOption{}
+			if mount.Size > 0 {
+				tmpfsOpts = append(tmpfsOpts, llb.TmpfsSize(mount.Size))
+			}
+			opts = append(opts, llb.AddMount(mount.Target, llb.Scratch(), llb.Tmpfs(tmpfsOpts...)))
+		case plan.MountTypeBind:
+			var src llb.State
+			source := "/"
+
+			if mount.Local {
+				src = *g.LocalState
+				if mount.Source != "" {
+					source = mount.Source
+				}
+			} else {
+				node, exists := g.graph.GetNode(mount.Step)
+				if !exists {
+					return nil, fmt.Errorf("step %q mounted at %s not found", mount.Step, mount.Target)
+				}
+
+				src = g.GetStateForInput(plan.NewStepInput(node.GetName()))
+				if mount.Source != "" {
+					source, _ = resolvePaths(mount.Source)
+				}
+			}
+
+			opts = append(opts, llb.AddMount(mount.Target, src, llb.SourcePath(source), llb.Readonly))
+		}
+	}
+
+	return opts, nil
+}
@@ -15,13 +15,19 @@ import (
 
 type ConvertPlanOptions struct {
 	BuildPlatform BuildPlatform
-	SecretsHash   string
+	SecretHashes  map[string]string
 	CacheKey      string
 	SessionID     string
+
+	// A single hash of all secret values. Only used when SecretHashes is empty
+	SecretsHash string
 }
 
 const (
 	WorkingDir = "/app"
+
+	// Label used to expose the release command to the platform running the image
+	ReleaseCommandLabel = "com.railpack.release-command"
 )
 
 func ConvertPlanToLLB(plan *p.BuildPlan, opts ConvertPlanOptions) (*llb.State, *Image, error) {
@@ -35,7 +41,16 @@ func ConvertPlanToLLB(plan *p.BuildPlan, opts ConvertPlanOptions) (*llb.State, *
 	)
 
 	cacheStore := build_llb.NewBuildKitCacheStore(opts.CacheKey)
-	graph, err := build_llb.NewBuildGraph(plan, &localState, cacheStore, opts.SecretsHash, &platform)
+	secretHashes := opts.SecretHashes
+	if len(secretHashes) == 0 && opts.SecretsHash != "" {
+		// Any change to the secrets invalidates every step that uses a secret
+		secretHashes = make(map[string]string)
+		for _, secret := range plan.Secrets {
+			secretHashes[secret] = opts.SecretsHash
+		}
+	}
+
+	graph, err := build_llb.NewBuildGraph(plan, &localState, cacheStore, secretHashes, &platform)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -72,6 +87,12 @@ func ConvertPlanToLLB(plan *p.BuildPlan, opts ConvertPlanOptions) (*llb.State, *
 		},
 	}
 
+	if plan.Deploy.ReleaseCmd != "" {
+		image.Config.Labels = map[string]string{
+			ReleaseCommandLabel: plan.Deploy.ReleaseCmd,
+		}
+	}
+
 	return &state, &image, nil
 }
 
@@ -25,6 +25,7 @@ const (
 	// The default filename for the serialized Railpack plan
 	defaultRailpackPlan = "railpack-plan.json"
 
+	// A single hash of all secret values. Prefer passing the hash of each secret with SecretHashArgPrefix
 	secretsHash = "secrets-hash"
 
 	cacheKey = "cache-key"
@@ -46,6 +47,7 @@ func Build(ctx context.Context, c client.Client) (*client.Result, error) {
 
 	cacheKey := buildArgs[cacheKey]
 	secretsHash := buildArgs[secretsHash]
+	secretHashes := parseSecretHashes(buildArgs)
 
 	// TODO: Support building for multiple platforms
 	buildPlatform, err := validatePlatform(opts)
@@ -65,6 +67,7 @@ func Build(ctx context.Context, c client.Client) (*client.Result, error) {
 
 	llbState, image, err := ConvertPlanToLLB(plan, ConvertPlanOptions{
 		BuildPlatform: buildPlatform,
+		SecretHashes:  secretHashes,
 		SecretsHash:   secretsHash,
 		CacheKey:      cacheKey,
 		SessionID:     c.BuildOpts().SessionID,
@@ -1,6 +1,8 @@
 package buildkit
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -29,3 +31,81 @@ func TestParseBuildArgs(t *testing.T) {
 		}
 	}
 }
+
+func TestGetSecretHash(t *testing.T) {
+	// Matches `echo -n "STRIPE_LIVE_KEY=sk_live_asdf" | openssl dgst -sha256 -hmac railpack-test-key`
+	want := "76b9aa7231cbbde6e1d69b9b64cbd180ecf6171c81c10973eb41371e15b8e2a2"
+	if got := GetSecretHash("railpack-test-key", "STRIPE_LIVE_KEY", "sk_live_asdf"); got != want {
+		t.Errorf("GetSecretHash() = %q, want %q", got, want)
+	}
+
+	// The same value under a different name has a different hash
+	if GetSecretHash("key", "A", "value") == GetSecretHash("key", "B", "value") {
+		t.Errorf("GetSecretHash() should be keyed by the secret name")
+	}
+
+	// The same secret has a different hash with a different key
+	if GetSecretHash("key", "A", "value") == GetSecretHash("other", "A", "value") {
+		t.Errorf("GetSecretHash() should depend on the key")
+	}
+}
+
+func TestLoadSecretsHashKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "railpack", secretsHashKeyFile)
+
+	key, err := loadSecretsHashKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key) != 64 {
+		t.Errorf("loadSecretsHashKey() = %q, want 32 random bytes as hex", key)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("key file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	// The key is reused, so the hashes of unchanged secrets stay the same
+	again, err := loadSecretsHashKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != key {
+		t.Errorf("loadSecretsHashKey() = %q, want the saved key %q", again, key)
+	}
+
+	t.Setenv(SecretsHashKeyEnvVar, "from-env")
+	if got, _ := GetSecretsHashKey(); got != "from-env" {
+		t.Errorf("GetSecretsHashKey() = %q, want the key of %s", got, SecretsHashKeyEnvVar)
+	}
+}
+
+func TestParseSecretHashes(t *testing.T) {
+	buildArgs := parseBuildArgs(map[string]string{
+		"build-arg:secrets-hash-API_KEY":      "abc",
+		"build-arg:secrets-hash-DATABASE_URL": "def",
+		"build-arg:secrets-hash":              "legacy",
+		"build-arg:cache-key":                 "key",
+	})
+
+	got := parseSecretHashes(buildArgs)
+
+	want := map[string]string{
+		"API_KEY":      "abc",
+		"DATABASE_URL": "def",
+	}
+
+	if len(got) != len(want) {
+		t.Errorf("got %d secret hashes, want %d", len(got), len(want))
+	}
+
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("secret hash %q = %q, want %q", k, got[k], v)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package buildkit
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// SecretHashArgPrefix is the prefix of the frontend build args that hold the hash of a single secret
+	// (e.g. secrets-hash-STRIPE_LIVE_KEY)
+	SecretHashArgPrefix = "secrets-hash-"
+
+	// SecretsHashKeyEnvVar sets the key of the secret hashes instead of the key that is kept in the cache directory,
+	// e.g. for CI runners that share a BuildKit cache but not their home directory
+	SecretsHashKeyEnvVar = "RAILPACK_SECRETS_HASH_KEY"
+
+	secretsHashKeyFile = "secrets-hash-key"
+)
+
+// GetSecretHash returns the HMAC-SHA256 of `NAME=value` with the key.
+// The hashes are visible in the build args and the LLB of a build. Without the key, which never leaves the client,
+// they cannot be used to check guesses of a secret value.
+// This is the same as `echo -n "NAME=value" | openssl dgst -sha256 -hmac "$KEY"`
+func GetSecretHash(key, name, value string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(name + "=" + value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// GetSecretHashes returns the hash of each secret
+func GetSecretHashes(key string, secrets map[string]string) map[string]string {
+	hashes := make(map[string]string, len(secrets))
+	for name, value := range secrets {
+		hashes[name] = GetSecretHash(key, name, value)
+	}
+	return hashes
+}
+
+// GetSecretsHashKey returns the key of the secret hashes. The key is random and kept in the cache directory of the
+// user, so the hashes of unchanged secrets stay the same between builds and the cached layers are reused
+func GetSecretsHashKey() (string, error) {
+	if key := os.Getenv(SecretsHashKeyEnvVar); key != "" {
+		return key, nil
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the cache directory for the secrets hash key. Set %s instead: %w", SecretsHashKeyEnvVar, err)
+	}
+
+	return loadSecretsHashKey(filepath.Join(cacheDir, "railpack", secretsHashKeyFile))
+}
+
+// loadSecretsHashKey reads the key from the file, or creates it with a random key
+func loadSecretsHashKey(path string) (string, error) {
+	contents, err := os.ReadFile(path)
+	if err == nil && strings.TrimSpace(string(contents)) != "" {
+		return strings.TrimSpace(string(contents)), nil
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("failed to read the secrets hash key: %w", err)
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to create the secrets hash key: %w", err)
+	}
+	key := hex.EncodeToString(random)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", fmt.Errorf("failed to create the directory of the secrets hash key: %w", err)
+	}
+
+	if err := os.WriteFile(path, []byte(key+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("failed to save the secrets hash key: %w", err)
+	}
+
+	return key, nil
+}
+
+// parseSecretHashes collects the per-secret hashes from the frontend build args
+func parseSecretHashes(buildArgs map[string]string) map[string]string {
+	hashes := make(map[string]string)
+	for name, value := range buildArgs {
+		if secret, ok := strings.CutPrefix(name, SecretHashArgPrefix); ok && secret != "" {
+			hashes[secret] = value
+		}
+	}
+	return hashes
+}
@@ -2,7 +2,6 @@ package cli
 
 import (
 	"context"
-	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -75,20 +74,28 @@ var BuildCommand = &cli.Command{
 			return cli.Exit(err, 1)
 		}
 
-		secretsHash := getSecretsHash(env)
-
 		platform, err := getPlatform(cmd.String("platform"))
 		if err != nil {
 			return cli.Exit(err, 1)
 		}
 
-		err = buildkit.BuildWithBuildkitClient(app.Source, buildResult.Plan, buildkit.BuildWithBuildkitClientOptions{
+		appDir, ok := app.LocalDir()
+		if !ok {
+			return cli.Exit(fmt.Errorf("building requires the app to be a local directory, but it was read from %s", app.Source), 1)
+		}
+
+		secretsHashKey, err := buildkit.GetSecretsHashKey()
+		if err != nil {
+			return cli.Exit(err, 1)
+		}
+
+		err = buildkit.BuildWithBuildkitClient(appDir, buildResult.Plan, buildkit.BuildWithBuildkitClientOptions{
 			ImageName:    cmd.String("name"),
 			DumpLLB:      cmd.Bool("llb"),
 			OutputDir:    cmd.String("output"),
 			ProgressMode: cmd.String("progress"),
 			CacheKey:     cmd.String("cache-key"),
-			SecretsHash:  secretsHash,
+			SecretHashes: buildkit.GetSecretHashes(secretsHashKey, env.Variables),
 			Secrets:      env.Variables,
 			Platform:     platform,
 		})
@@ -123,13 +130,3 @@ func validateSecrets(plan *plan.BuildPlan, env *app.Environment) error {
 	}
 	return nil
 }
-
-func getSecretsHash(env *app.Environment) string {
-	secretsValue := ""
-	for _, v := range env.Variables {
-		secretsValue += v
-	}
-	hasher := sha256.New()
-	hasher.Write([]byte(secretsValue))
-	return fmt.Sprintf("%x", hasher.Sum(nil))
-}
@@ -2,10 +2,12 @@ package cli
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/charmbracelet/log"
 	"github.com/unbindapp/railpack/core"
 	a "github.com/unbindapp/railpack/core/app"
+	"github.com/unbindapp/railpack/core/resolver"
 	"github.com/unbindapp/railpack/internal/utils"
 	"github.com/urfave/cli/v3"
 )
@@ -18,6 +20,20 @@ func commonPlanFlags() []cli.Flag {
 			Name:  "env",
 			Usage: "environment variables to set",
 		},
+		&cli.StringSliceFlag{
+			Name:    "env-file",
+			Usage:   "path to a dotenv file with environment variables to set. Variables from --env take precedence",
+			Sources: cli.EnvVars("RAILPACK_ENV_FILE"),
+		},
+		&cli.StringFlag{
+			Name:  "env-file-as",
+			Usage: "how variables from --env-file are used (secrets or build-args)",
+			Value: a.EnvFileAsSecrets,
+		},
+		&cli.StringSliceFlag{
+			Name:  "build-arg",
+			Usage: "build args to set. Unlike --env, these are plain variables that are visible in the build plan",
+		},
 		&cli.StringSliceFlag{
 			Name:  "previous",
 			Usage: "versions of packages used for previous builds (e.g. 'package@version')",
@@ -30,6 +46,10 @@ func commonPlanFlags() []cli.Flag {
 			Name:  "start-cmd",
 			Usage: "start command to use",
 		},
+		&cli.StringFlag{
+			Name:  "release-cmd",
+			Usage: "release command to run before the new deploy receives traffic",
+		},
 		&cli.StringFlag{
 			Name:  "config-file",
 			Usage: "path to config file to use",
@@ -38,17 +58,77 @@ func commonPlanFlags() []cli.Flag {
 			Name:  "error-missing-start",
 			Usage: "error if no start command is found",
 		},
+		&cli.StringFlag{
+			Name:  "git-ref",
+			Usage: "read the app from this commit of the git repository at the directory instead of its working tree",
+		},
+		&cli.BoolFlag{
+			Name:    "frozen-lockfile",
+			Usage:   "error if the packages or images differ from railpack.lock",
+			Sources: cli.EnvVars("RAILPACK_FROZEN_LOCKFILE"),
+		},
+		&cli.StringSliceFlag{
+			Name:    "version-source",
+			Aliases: []string{"version-index"},
+			Usage:   "where to resolve package versions from: `mise`, the URL of an index server, or a version index file. Can be repeated to fall back to the next source, and `PACKAGE=SOURCE` routes a package to one source",
+			Sources: cli.EnvVars("RAILPACK_VERSION_SOURCE", "RAILPACK_VERSION_INDEX"),
+		},
+		&cli.DurationFlag{
+			Name:    "version-cache-ttl",
+			Usage:   "how long the package versions listed by mise are cached. 0 disables the cache",
+			Value:   resolver.DefaultVersionCacheTTL,
+			Sources: cli.EnvVars("RAILPACK_VERSION_CACHE_TTL"),
+		},
+		&cli.BoolFlag{
+			Name:    "refresh-versions",
+			Usage:   "list package versions again instead of using the version cache",
+			Sources: cli.EnvVars("RAILPACK_REFRESH_VERSIONS"),
+		},
+		&cli.BoolFlag{
+			Name:    "fail-on-eol",
+			Usage:   "error instead of warning if a resolved package is end-of-life or has known vulnerabilities",
+			Sources: cli.EnvVars("RAILPACK_FAIL_ON_EOL"),
+		},
+		&cli.StringFlag{
+			Name:    "eol-rules",
+			Usage:   "path to a JSON file with EOL rules that override the built-in ones",
+			Sources: cli.EnvVars("RAILPACK_EOL_RULES"),
+		},
+		&cli.StringSliceFlag{
+			Name:    "plugin-dir",
+			Usage:   "directory or file with provider plugins to load. Can be YAML providers or exec plugins",
+			Sources: cli.EnvVars("RAILPACK_PLUGIN_DIR"),
+		},
 	}
 }
 
+// newApp reads the app from a git commit when a ref is given, from a tar archive when the source is a file,
+// and from the directory otherwise
+func newApp(source, gitRef string) (*a.App, error) {
+	if gitRef != "" {
+		return a.NewAppFromGit(source, gitRef)
+	}
+
+	if info, err := os.Stat(source); err == nil && !info.IsDir() {
+		return a.NewAppFromTar(source)
+	}
+
+	return a.NewApp(source)
+}
+
 func GenerateBuildResultForCommand(cmd *cli.Command) (*core.BuildResult, *a.App, *a.Environment, error) {
+	return generateBuildResult(cmd, nil)
+}
+
+// generateBuildResult generates the build result for the command. The options can be changed before planning
+func generateBuildResult(cmd *cli.Command, configure func(options *core.GenerateBuildPlanOptions)) (*core.BuildResult, *a.App, *a.Environment, error) {
 	directory := cmd.Args().First()
 
 	if directory == "" {
 		return nil, nil, nil, cli.Exit("directory argument is required", 1)
 	}
 
-	app, err := a.NewApp(directory)
+	app, err := newApp(directory, cmd.String("git-ref"))
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("error creating app: %w", err)
 	}
@@ -61,6 +141,11 @@ func GenerateBuildResultForCommand(cmd *cli.Command) (*core.BuildResult, *a.App,
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("error creating env: %w", err)
 	}
+	env.AddBuildArgs(cmd.StringSlice("build-arg"))
+
+	if err := env.AddEnvFiles(cmd.StringSlice("env-file"), cmd.String("env-file-as")); err != nil {
+		return nil, nil, nil, fmt.Errorf("error loading env files: %w", err)
+	}
 
 	previousVersions := utils.ParsePackageWithVersion(cmd.StringSlice("previous"))
 
@@ -68,9 +153,21 @@ func GenerateBuildResultForCommand(cmd *cli.Command) (*core.BuildResult, *a.App,
 		RailpackVersion:          Version,
 		BuildCommand:             cmd.String("build-cmd"),
 		StartCommand:             cmd.String("start-cmd"),
+		ReleaseCommand:           cmd.String("release-cmd"),
 		PreviousVersions:         previousVersions,
 		ConfigFilePath:           cmd.String("config-file"),
 		ErrorMissingStartCommand: cmd.Bool("error-missing-start"),
+		PluginPaths:              cmd.StringSlice("plugin-dir"),
+		FrozenLockfile:           cmd.Bool("frozen-lockfile"),
+		VersionSources:           cmd.StringSlice("version-source"),
+		VersionCacheTTL:          cmd.Duration("version-cache-ttl"),
+		RefreshVersions:          cmd.Bool("refresh-versions"),
+		EOLRulesPath:             cmd.String("eol-rules"),
+		FailOnEOL:                cmd.Bool("fail-on-eol"),
+	}
+
+	if configure != nil {
+		configure(generateOptions)
 	}
 
 	buildResult := core.GenerateBuildPlan(app, env, generateOptions)
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/charmbracelet/log"
+	"github.com/unbindapp/railpack/core/mise"
+	"github.com/unbindapp/railpack/core/resolver"
+	"github.com/urfave/cli/v3"
+)
+
+// defaultIndexTools are the tools that the providers install with mise
+var defaultIndexTools = []string{
+	"bun", "caddy", "deno", "go", "gradle", "java", "maven", "node", "php", "pipx", "pnpm", "python", "yarn",
+}
+
+var IndexCommand = &cli.Command{
+	Name:  "index",
+	Usage: "manage version indexes for resolving package versions without network access",
+	Commands: []*cli.Command{
+		indexExportCommand,
+	},
+}
+
+var indexExportCommand = &cli.Command{
+	Name:  "export",
+	Usage: "list the available versions of tools with mise and write them to a version index",
+	Flags: []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "tool",
+			Usage: "tool to include in the index. Defaults to the tools used by the providers and the tools already in the output file",
+		},
+		&cli.StringFlag{
+			Name:    "out",
+			Aliases: []string{"o"},
+			Usage:   "output file name. If the file exists, its tools are refreshed",
+		},
+	},
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		output := cmd.String("out")
+
+		index := resolver.NewVersionIndex()
+		if output != "" {
+			if _, err := os.Stat(output); err == nil {
+				index, err = resolver.ReadVersionIndex(output)
+				if err != nil {
+					return cli.Exit(err, 1)
+				}
+			}
+		}
+
+		tools := cmd.StringSlice("tool")
+		if len(tools) == 0 {
+			tools = slices.Clone(defaultIndexTools)
+			for tool := range index.Tools {
+				tools = append(tools, tool)
+			}
+		}
+		slices.Sort(tools)
+		tools = slices.Compact(tools)
+
+		m, err := mise.New(mise.InstallDir)
+		if err != nil {
+			return cli.Exit(err, 1)
+		}
+
+		for _, tool := range tools {
+			versions, err := m.ListVersions(tool)
+			if err != nil {
+				return cli.Exit(fmt.Errorf("failed to list versions of %s: %w", tool, err), 1)
+			}
+
+			index.Set(tool, versions)
+			log.Debugf("Found %d versions of %s", len(versions), tool)
+		}
+
+		data, err := index.Marshal()
+		if err != nil {
+			return cli.Exit(err, 1)
+		}
+
+		if output == "" {
+			os.Stdout.Write(data)
+			return nil
+		}
+
+		if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+			return cli.Exit(err, 1)
+		}
+
+		if err := os.WriteFile(output, data, 0644); err != nil {
+			return cli.Exit(err, 1)
+		}
+
+		log.Infof("Version index with %d tools written to %s", len(tools), output)
+
+		return nil
+	},
+}
@@ -39,8 +39,9 @@ var InfoCommand = &cli.Command{
 		var buildResultString string
 		if format == "pretty" {
 			buildResultString = core.FormatBuildResult(buildResult, core.PrintOptions{
-				Metadata: true,
-				Version:  Version,
+				Metadata:  true,
+				Providers: true,
+				Version:   Version,
 			})
 		} else {
 			serializedResult, err := json.MarshalIndent(buildResult, "", "  ")
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/charmbracelet/log"
+	"github.com/unbindapp/railpack/core"
+	"github.com/unbindapp/railpack/core/lockfile"
+	"github.com/urfave/cli/v3"
+)
+
+var LockCommand = &cli.Command{
+	Name:                  "lock",
+	Usage:                 "resolve package versions and image digests and write them to railpack.lock",
+	ArgsUsage:             "DIRECTORY",
+	EnableShellCompletion: true,
+	Flags: append([]cli.Flag{
+		&cli.BoolFlag{
+			Name:  "update",
+			Usage: "resolve all versions and digests again instead of keeping the locked ones",
+		},
+	}, commonPlanFlags()...),
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		buildResult, app, _, err := generateBuildResult(cmd, func(options *core.GenerateBuildPlanOptions) {
+			options.IgnoreLockfile = cmd.Bool("update")
+		})
+		if err != nil {
+			return cli.Exit(err, 1)
+		}
+
+		if !buildResult.Success {
+			core.PrettyPrintBuildResult(buildResult, core.PrintOptions{Version: Version})
+			os.Exit(1)
+			return nil
+		}
+
+		appDir, ok := app.LocalDir()
+		if !ok {
+			return cli.Exit(fmt.Errorf("locking requires the app to be a local directory, but it was read from %s", app.Source), 1)
+		}
+
+		// Images that are already pinned by the lockfile keep their digest
+		images := lockfile.PlanImages(buildResult.Plan)
+		for _, ref := range slices.Sorted(maps.Keys(images)) {
+			if images[ref] != "" {
+				continue
+			}
+
+			digest, err := lockfile.ResolveImageDigest(ctx, ref)
+			if err != nil {
+				return cli.Exit(err, 1)
+			}
+			images[ref] = digest
+		}
+
+		previous, err := lockfile.Read(app)
+		if err != nil {
+			return cli.Exit(err, 1)
+		}
+
+		lock := lockfile.New(buildResult.ResolvedPackages, images)
+		data, err := lock.Marshal()
+		if err != nil {
+			return cli.Exit(err, 1)
+		}
+
+		lockPath := filepath.Join(appDir, lockfile.FileName)
+		if err := os.WriteFile(lockPath, data, 0644); err != nil {
+			return cli.Exit(err, 1)
+		}
+
+		changes := lock.Diff(previous)
+		if len(changes) == 0 {
+			log.Infof("%s is up to date", lockPath)
+			return nil
+		}
+
+		for _, change := range changes {
+			log.Info(change)
+		}
+		log.Infof("Lockfile written to %s", lockPath)
+
+		return nil
+	},
+}
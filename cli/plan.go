@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 
 	"github.com/charmbracelet/log"
+	"github.com/unbindapp/railpack/core/logger"
 	"github.com/urfave/cli/v3"
 )
 
@@ -29,6 +30,22 @@ var PlanCommand = &cli.Command{
 			return cli.Exit(err, 1)
 		}
 
+		// The plan is written to stdout, so errors are only logged to stderr
+		if !buildResult.Success {
+			for _, msg := range buildResult.Logs {
+				if msg.Level == logger.Error {
+					log.Error(msg.Msg)
+				}
+			}
+			return cli.Exit("failed to generate a build plan", 1)
+		}
+
+		for _, msg := range buildResult.Logs {
+			if msg.Level == logger.Warn {
+				log.Warn(msg.Msg)
+			}
+		}
+
 		serializedPlan, err := json.MarshalIndent(buildResult.Plan, "", "  ")
 		if err != nil {
 			return cli.Exit(err, 1)
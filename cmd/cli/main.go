@@ -35,6 +35,8 @@ func main() {
 		cli.PrepareCommand,
 		cli.InfoCommand,
 		cli.PlanCommand,
+		cli.LockCommand,
+		cli.IndexCommand,
 		cli.SchemaCommand,
 		cli.FrontendCommand,
 	}
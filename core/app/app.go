@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -16,7 +18,11 @@ import (
 )
 
 type App struct {
+	// Source describes where the app is read from. For local apps it is the absolute path of the directory
 	Source string
+
+	fsys fs.FS
+	dir  string
 }
 
 func NewApp(path string) (*App, error) {
@@ -42,7 +48,22 @@ func NewApp(path string) (*App, error) {
 		return nil, fmt.Errorf("failed to check directory %s: %w", source, err)
 	}
 
-	return &App{Source: source}, nil
+	return &App{Source: source, fsys: os.DirFS(source), dir: source}, nil
+}
+
+// NewAppFromFS creates an app that reads its files from fsys. The source is only used to describe the app
+func NewAppFromFS(source string, fsys fs.FS) *App {
+	return &App{Source: source, fsys: fsys}
+}
+
+// FS returns the file system the app is read from
+func (a *App) FS() fs.FS {
+	return a.fsys
+}
+
+// LocalDir returns the directory of the app if it is read from the local file system
+func (a *App) LocalDir() (string, bool) {
+	return a.dir, a.dir != ""
 }
 
 // findMatches returns a list of paths matching a glob pattern, filtered by isDir
@@ -55,9 +76,7 @@ func (a *App) findMatches(pattern string, isDir bool) ([]string, error) {
 
 	var paths []string
 	for _, match := range matches {
-		fullPath := filepath.Join(a.Source, match)
-
-		info, err := os.Stat(fullPath)
+		info, err := fs.Stat(a.fsys, match)
 		if err != nil {
 			continue
 		}
@@ -81,7 +100,7 @@ func (a *App) FindDirectories(pattern string) ([]string, error) {
 
 // findGlob finds paths matching a glob pattern
 func (a *App) findGlob(pattern string) ([]string, error) {
-	matches, err := doublestar.Glob(os.DirFS(a.Source), pattern)
+	matches, err := doublestar.Glob(a.fsys, pattern)
 
 	if err != nil {
 		return nil, err
@@ -128,11 +147,9 @@ func (a *App) FindFilesWithContent(pattern string, regex *regexp.Regexp) []strin
 
 // ReadFile reads the contents of a file
 func (a *App) ReadFile(name string) (string, error) {
-	path := filepath.Join(a.Source, name)
-	data, err := os.ReadFile(path)
+	data, err := fs.ReadFile(a.fsys, cleanPath(name))
 	if err != nil {
-		relativePath, _ := a.stripSourcePath(path)
-		return "", fmt.Errorf("error reading %s: %w", relativePath, err)
+		return "", fmt.Errorf("error reading %s: %w", cleanPath(name), err)
 	}
 
 	return strings.ReplaceAll(string(data), "\r\n", "\n"), nil
@@ -153,8 +170,7 @@ func (a *App) ReadJSON(name string, v interface{}) error {
 	data = string(jsonBytes)
 
 	if err := json.Unmarshal([]byte(data), v); err != nil {
-		relativePath, _ := a.stripSourcePath(filepath.Join(a.Source, name))
-		return fmt.Errorf("error reading %s as JSON: %w", relativePath, err)
+		return fmt.Errorf("error reading %s as JSON: %w", cleanPath(name), err)
 	}
 
 	return nil
@@ -186,8 +202,7 @@ func (a *App) ReadTOML(name string, v interface{}) error {
 
 // IsFileExecutable checks if a path is an executable file
 func (a *App) IsFileExecutable(name string) bool {
-	path := filepath.Join(a.Source, name)
-	info, err := os.Stat(path)
+	info, err := fs.Stat(a.fsys, cleanPath(name))
 	if err != nil {
 		return false
 	}
@@ -200,13 +215,10 @@ func (a *App) IsFileExecutable(name string) bool {
 	return info.Mode()&0111 != 0
 }
 
-// StripSourcePath converts an absolute path to a path relative to the app source directory
-func (a *App) stripSourcePath(absPath string) (string, error) {
-	rel, err := filepath.Rel(a.Source, absPath)
-	if err != nil {
-		return "", errors.New("failed to parse source path")
-	}
-	return rel, nil
+// cleanPath converts a path relative to the app into a path of the app file system
+func cleanPath(name string) string {
+	name = path.Clean(filepath.ToSlash(name))
+	return strings.TrimPrefix(name, "/")
 }
 
 func standardizeJSON(b []byte) ([]byte, error) {
@@ -0,0 +1,205 @@
+package app
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+)
+
+// maxCachedFileSize is the largest file that is kept in memory when an archive is read. Larger files are read
+// again from the archive when they are used
+const maxCachedFileSize = 1 << 20
+
+// NewAppFromTar reads an app from a tar or tar.gz archive without unpacking it to disk.
+// Only the list of files is read up front. Contents are read from the archive when a file is used
+func NewAppFromTar(archivePath string) (*App, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive %s: %w", archivePath, err)
+	}
+	defer file.Close()
+
+	return readTar(archivePath, file, func() (*os.File, error) { return os.Open(archivePath) })
+}
+
+// NewAppFromTarReader reads an app from a tar or tar.gz stream. Gzip compression is detected from the contents.
+// The source is only used to describe the app. A stream can not be read again, so files larger than
+// maxCachedFileSize can be listed but not read
+func NewAppFromTarReader(source string, r io.Reader) (*App, error) {
+	return readTar(source, r, nil)
+}
+
+// readTar lists the files of an archive. When reopen is set, the contents of files are read from the reopened
+// archive instead of being kept in memory
+func readTar(source string, r io.Reader, reopen func() (*os.File, error)) (*App, error) {
+	reader := bufio.NewReader(r)
+
+	compressed, err := isGzip(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive %s: %w", source, err)
+	}
+
+	// The offsets of uncompressed archives are the offsets of the entries in the file
+	counter := &countingReader{r: reader}
+	var archive io.Reader = counter
+	if compressed {
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress archive %s: %w", source, err)
+		}
+		defer gzipReader.Close()
+		archive = gzipReader
+	}
+
+	tree := newTreeFS()
+	tarReader := tar.NewReader(archive)
+
+	for index := 0; ; index++ {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive %s: %w", source, err)
+		}
+
+		name, ok := archivePath(header.Name)
+		if !ok {
+			continue
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			tree.addDir(name)
+		case tar.TypeReg:
+			var load func() ([]byte, error)
+			switch {
+			case reopen != nil && !compressed && !isSparse(header):
+				load = sectionContents(reopen, counter.n, header.Size)
+			case header.Size <= maxCachedFileSize:
+				data, err := io.ReadAll(tarReader)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read %s from archive %s: %w", name, source, err)
+				}
+				load = staticContents(data)
+			case reopen != nil:
+				load = entryContents(reopen, index)
+			default:
+				load = func() ([]byte, error) {
+					return nil, fmt.Errorf("%s is larger than %d bytes, so it can not be read from a stream", name, maxCachedFileSize)
+				}
+			}
+			tree.addFile(name, fs.FileMode(header.Mode).Perm(), header.Size, load)
+		case tar.TypeSymlink:
+			tree.addSymlink(name, header.Linkname)
+		case tar.TypeLink:
+			// Hard links point to a file earlier in the archive
+			target, ok := archivePath(header.Linkname)
+			if !ok {
+				continue
+			}
+			if entry, ok := tree.entries[target]; ok && entry.mode.IsRegular() {
+				tree.addFile(name, entry.mode, entry.size, entry.contents)
+			}
+		}
+	}
+
+	return NewAppFromFS(source, tree), nil
+}
+
+func isGzip(reader *bufio.Reader) (bool, error) {
+	magic, err := reader.Peek(2)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	return bytes.Equal(magic, []byte{0x1f, 0x8b}), nil
+}
+
+// isSparse checks if the contents of an entry are stored as sparse data instead of as is
+func isSparse(header *tar.Header) bool {
+	for key := range header.PAXRecords {
+		if strings.HasPrefix(key, "GNU.sparse.") {
+			return true
+		}
+	}
+	return false
+}
+
+// sectionContents reads the contents of a file at its offset in an uncompressed archive
+func sectionContents(reopen func() (*os.File, error), offset, size int64) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		file, err := reopen()
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		data := make([]byte, size)
+		if _, err := file.ReadAt(data, offset); err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+}
+
+// entryContents reads the contents of the entry at the index of a compressed archive, which has to be
+// decompressed up to the entry
+func entryContents(reopen func() (*os.File, error), index int) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		file, err := reopen()
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		gzipReader, err := gzip.NewReader(bufio.NewReader(file))
+		if err != nil {
+			return nil, err
+		}
+		defer gzipReader.Close()
+
+		tarReader := tar.NewReader(gzipReader)
+		for i := 0; i <= index; i++ {
+			if _, err := tarReader.Next(); err != nil {
+				return nil, err
+			}
+		}
+
+		return io.ReadAll(tarReader)
+	}
+}
+
+// countingReader counts the bytes that are read
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// archivePath converts the name of an archive entry to a path in the app
+func archivePath(name string) (string, bool) {
+	name = path.Clean(strings.TrimPrefix(name, "/"))
+	if name == "." || !fs.ValidPath(name) {
+		return "", false
+	}
+	return name, true
+}
+
+func staticContents(data []byte) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		return data, nil
+	}
+}
@@ -0,0 +1,212 @@
+package app
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Matches ${VAR}, ${VAR:-default}, and $VAR
+var dotenvInterpolationRe = regexp.MustCompile(`\\?\$(?:\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}|([A-Za-z_][A-Za-z0-9_]*))`)
+
+var dotenvKeyRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// ReadEnvFile reads and parses a dotenv file. References to variables that are not
+// defined earlier in the file are resolved from the current environment
+func ReadEnvFile(path string) (map[string]string, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read env file %s: %w", path, err)
+	}
+
+	variables, err := ParseDotenv(string(contents), os.LookupEnv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse env file %s: %w", path, err)
+	}
+
+	return variables, nil
+}
+
+// ParseDotenv parses the contents of a dotenv file.
+//
+// It supports comments, `export` prefixes, single quoted literal values, double quoted values with
+// escapes, multiline quoted values, and interpolation of ${VAR}, ${VAR:-default}, and $VAR in
+// unquoted and double quoted values. Variables are resolved from earlier in the file, then from lookup
+func ParseDotenv(contents string, lookup func(string) (string, bool)) (map[string]string, error) {
+	variables := map[string]string{}
+	resolve := func(name string) (string, bool) {
+		if value, ok := variables[name]; ok {
+			return value, true
+		}
+		if lookup != nil {
+			return lookup(name)
+		}
+		return "", false
+	}
+
+	rest := strings.ReplaceAll(contents, "\r\n", "\n")
+	lineNumber := 0
+
+	for rest != "" {
+		var line string
+		line, rest = cutLine(rest)
+		lineNumber++
+
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !found || !dotenvKeyRe.MatchString(key) {
+			return nil, fmt.Errorf("line %d: invalid line `%s`. Expected KEY=value", lineNumber, line)
+		}
+
+		value = strings.TrimLeft(value, " \t")
+
+		switch {
+		case strings.HasPrefix(value, "'"):
+			quoted, remaining, consumed, err := readQuoted(value, rest, '\'')
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			rest = remaining
+			lineNumber += consumed
+			variables[key] = quoted
+
+		case strings.HasPrefix(value, `"`):
+			quoted, remaining, consumed, err := readQuoted(value, rest, '"')
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			rest = remaining
+			lineNumber += consumed
+			variables[key] = interpolateDotenv(unescapeDotenv(quoted), resolve)
+
+		default:
+			// Unquoted values end at an inline comment
+			if idx := strings.Index(value, " #"); idx >= 0 {
+				value = value[:idx]
+			}
+			variables[key] = interpolateDotenv(strings.TrimSpace(value), resolve)
+		}
+	}
+
+	return variables, nil
+}
+
+func cutLine(s string) (string, string) {
+	line, rest, _ := strings.Cut(s, "\n")
+	return line, rest
+}
+
+// readQuoted reads a quoted value that can span multiple lines.
+// It returns the value without quotes, the remaining contents, and the number of extra lines read
+func readQuoted(value, rest string, quote byte) (string, string, int, error) {
+	buf := value[1:]
+	consumed := 0
+
+	for {
+		if end := closingQuote(buf, quote); end >= 0 {
+			// Anything after the closing quote must be a comment
+			trailing := strings.TrimSpace(buf[end+1:])
+			if trailing != "" && !strings.HasPrefix(trailing, "#") {
+				return "", "", 0, fmt.Errorf("unexpected characters after closing quote: %s", trailing)
+			}
+			return buf[:end], rest, consumed, nil
+		}
+
+		if rest == "" {
+			return "", "", 0, fmt.Errorf("missing closing quote %c", quote)
+		}
+
+		var line string
+		line, rest = cutLine(rest)
+		buf += "\n" + line
+		consumed++
+	}
+}
+
+// closingQuote returns the index of the first unescaped quote
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		if quote == '"' && s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+func unescapeDotenv(s string) string {
+	replacer := strings.NewReplacer(`\n`, "\n", `\r`, "\r", `\t`, "\t", `\"`, `"`, `\\`, `\`)
+	return replacer.Replace(s)
+}
+
+func interpolateDotenv(value string, resolve func(string) (string, bool)) string {
+	return dotenvInterpolationRe.ReplaceAllStringFunc(value, func(match string) string {
+		// Escaped references are kept literally
+		if strings.HasPrefix(match, `\`) {
+			return match[1:]
+		}
+
+		parts := dotenvInterpolationRe.FindStringSubmatch(match)
+		name, defaultValue := parts[1], parts[2]
+		if name == "" {
+			name = parts[3]
+		}
+
+		if resolved, ok := resolve(name); ok && resolved != "" {
+			return resolved
+		}
+
+		return defaultValue
+	})
+}
+
+const (
+	EnvFileAsSecrets   = "secrets"
+	EnvFileAsBuildArgs = "build-args"
+)
+
+// AddEnvFiles loads the variables of each env file as secrets or build args.
+// Later files take precedence over earlier ones, but variables already in the Environment are kept
+func (e *Environment) AddEnvFiles(paths []string, as string) error {
+	var set func(name, value string)
+	var existing map[string]string
+
+	switch as {
+	case "", EnvFileAsSecrets:
+		set, existing = e.SetVariable, e.Variables
+	case EnvFileAsBuildArgs:
+		set, existing = e.SetBuildArg, e.BuildArgs
+	default:
+		return fmt.Errorf("invalid env file type `%s`. Expected %s or %s", as, EnvFileAsSecrets, EnvFileAsBuildArgs)
+	}
+
+	fromFiles := map[string]string{}
+	for _, path := range paths {
+		variables, err := ReadEnvFile(path)
+		if err != nil {
+			return err
+		}
+		maps.Copy(fromFiles, variables)
+	}
+
+	for name, value := range fromFiles {
+		if _, ok := existing[name]; ok {
+			continue
+		}
+		set(name, value)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDotenv(t *testing.T) {
+	contents := `# A comment
+PLAIN=value
+export EXPORTED=exported
+SPACES = spaced out  # inline comment
+SINGLE='literal $PLAIN \n'
+DOUBLE="quoted \"$PLAIN\"\tand\nescaped"
+MULTILINE="first
+second"
+SINGLE_MULTILINE='-----BEGIN KEY-----
+abc
+-----END KEY-----'
+BRACES=${PLAIN}-${MISSING:-fallback}
+FROM_LOOKUP=$SHELL_VAR
+ESCAPED="\$PLAIN"
+EMPTY=
+HASH=no#comment
+`
+
+	lookup := func(name string) (string, bool) {
+		if name == "SHELL_VAR" {
+			return "from-shell", true
+		}
+		return "", false
+	}
+
+	variables, err := ParseDotenv(contents, lookup)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"PLAIN":            "value",
+		"EXPORTED":         "exported",
+		"SPACES":           "spaced out",
+		"SINGLE":           `literal $PLAIN \n`,
+		"DOUBLE":           "quoted \"value\"\tand\nescaped",
+		"MULTILINE":        "first\nsecond",
+		"SINGLE_MULTILINE": "-----BEGIN KEY-----\nabc\n-----END KEY-----",
+		"BRACES":           "value-fallback",
+		"FROM_LOOKUP":      "from-shell",
+		"ESCAPED":          "$PLAIN",
+		"EMPTY":            "",
+		"HASH":             "no#comment",
+	}, variables)
+}
+
+func TestParseDotenvErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		err      string
+	}{
+		{name: "missing equals", contents: "A=1\nINVALID", err: "line 2"},
+		{name: "invalid key", contents: "1A=1", err: "line 1"},
+		{name: "unclosed quote", contents: "A=\"value\nB=2", err: "missing closing quote"},
+		{name: "trailing characters", contents: "A='value' extra", err: "unexpected characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDotenv(tt.contents, nil)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestAddEnvFiles(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, ".env")
+	second := filepath.Join(dir, ".env.local")
+	require.NoError(t, os.WriteFile(first, []byte("A=1\nB=2\nC=3\n"), 0644))
+	require.NoError(t, os.WriteFile(second, []byte("B=local\n"), 0644))
+
+	env, err := FromEnvs([]string{"C=cli"})
+	require.NoError(t, err)
+	require.NoError(t, env.AddEnvFiles([]string{first, second}, EnvFileAsSecrets))
+	require.Equal(t, map[string]string{"A": "1", "B": "local", "C": "cli"}, env.Variables)
+
+	env, err = FromEnvs([]string{})
+	require.NoError(t, err)
+	require.NoError(t, env.AddEnvFiles([]string{first}, EnvFileAsBuildArgs))
+	require.Empty(t, env.Variables)
+	require.Equal(t, map[string]string{"A": "1", "B": "2", "C": "3"}, env.BuildArgs)
+
+	require.Error(t, env.AddEnvFiles([]string{first}, "unknown"))
+	require.Error(t, env.AddEnvFiles([]string{filepath.Join(dir, "missing")}, EnvFileAsSecrets))
+}
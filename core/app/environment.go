@@ -8,24 +8,39 @@ import (
 )
 
 type Environment struct {
+	// Variables are treated as secrets during the build
 	Variables map[string]string
+
+	// BuildArgs are plain variables that are visible in the build plan
+	BuildArgs map[string]string
 }
 
+var envPairRe = regexp.MustCompile(`([A-Za-z0-9_-]*)(?:=?)(.*)`)
+
 func NewEnvironment(variables *map[string]string) *Environment {
 	if variables == nil {
 		variables = &map[string]string{}
 	}
 
-	return &Environment{Variables: *variables}
+	return &Environment{Variables: *variables, BuildArgs: map[string]string{}}
 }
 
 // FromEnvs collects variables from the given environment variable names
 func FromEnvs(envs []string) (*Environment, error) {
 	env := NewEnvironment(nil)
-	re := regexp.MustCompile(`([A-Za-z0-9_-]*)(?:=?)(.*)`)
+	parseEnvPairs(envs, env.SetVariable)
+	return env, nil
+}
 
-	for _, e := range envs {
-		matches := re.FindStringSubmatch(e)
+// AddBuildArgs collects build args from the given NAME=value pairs.
+// Build args without a value are pulled from the current environment
+func (e *Environment) AddBuildArgs(args []string) {
+	parseEnvPairs(args, e.SetBuildArg)
+}
+
+func parseEnvPairs(pairs []string, set func(name, value string)) {
+	for _, pair := range pairs {
+		matches := envPairRe.FindStringSubmatch(pair)
 		if len(matches) < 3 {
 			continue
 		}
@@ -36,20 +51,29 @@ func FromEnvs(envs []string) (*Environment, error) {
 		if value == "" {
 			// No value, pull from current environment
 			if v, ok := os.LookupEnv(name); ok {
-				env.SetVariable(name, v)
+				set(name, v)
 			}
 		} else {
 			// Use provided name, value pair
-			env.SetVariable(name, value)
+			set(name, value)
 		}
 	}
-
-	return env, nil
 }
 
-// GetVariable returns the value of the given variable name
+// GetVariable returns the value of the given variable name, falling back to the build args
 func (e *Environment) GetVariable(name string) string {
-	return e.Variables[name]
+	if value, ok := e.Variables[name]; ok {
+		return value
+	}
+	return e.BuildArgs[name]
+}
+
+// SetBuildArg stores a build arg in the Environment
+func (e *Environment) SetBuildArg(name, value string) {
+	if e.BuildArgs == nil {
+		e.BuildArgs = map[string]string{}
+	}
+	e.BuildArgs[name] = value
 }
 
 // SetVariable stores a variable in the Environment
@@ -70,6 +94,9 @@ func (e *Environment) GetConfigVariable(name string) (string, string) {
 	if val, exists := e.Variables[configVar]; exists {
 		return strings.TrimSpace(val), configVar
 	}
+	if val, exists := e.BuildArgs[configVar]; exists {
+		return strings.TrimSpace(val), configVar
+	}
 	return "", ""
 }
 
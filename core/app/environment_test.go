@@ -20,3 +20,19 @@ func TestFromEnvs(t *testing.T) {
 	require.Equal(t, env.GetVariable("RAILPACK_APT_PACKAGES"), "apt1,apt2")
 	require.Equal(t, env.GetVariable("COMMA"), "this has, a comma")
 }
+
+func TestAddBuildArgs(t *testing.T) {
+	t.Setenv("FROM_SHELL", "shell-value")
+
+	env, err := FromEnvs([]string{"SECRET=value"})
+	require.NoError(t, err)
+
+	env.AddBuildArgs([]string{"NODE_ENV=production", "FROM_SHELL", "RAILPACK_BUILD_CMD=make"})
+
+	require.Equal(t, map[string]string{"SECRET": "value"}, env.Variables)
+	require.Equal(t, "production", env.GetVariable("NODE_ENV"))
+	require.Equal(t, "shell-value", env.GetVariable("FROM_SHELL"))
+
+	buildCmd, _ := env.GetConfigVariable("BUILD_CMD")
+	require.Equal(t, "make", buildCmd)
+}
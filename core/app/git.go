@@ -0,0 +1,102 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// NewAppFromGit reads an app from a commit of a bare or non-bare git repository.
+// Files are read from the object database, so the working tree is ignored
+func NewAppFromGit(repoPath, rev string) (*App, error) {
+	if rev == "" {
+		rev = "HEAD"
+	}
+
+	commit, err := runGit(repoPath, "rev-parse", "--verify", "--end-of-options", rev+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find commit %s in %s: %w", rev, repoPath, err)
+	}
+	commit = bytes.TrimSpace(commit)
+
+	tree, err := runGit(repoPath, "ls-tree", "-r", "-t", "-l", "-z", "--full-tree", string(commit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files of commit %s in %s: %w", rev, repoPath, err)
+	}
+
+	treeFS := newTreeFS()
+
+	for _, line := range bytes.Split(tree, []byte{0}) {
+		if len(line) == 0 {
+			continue
+		}
+
+		// <mode> SP <type> SP <object> SP+ <size> TAB <path>
+		meta, name, ok := strings.Cut(string(line), "\t")
+		if !ok {
+			return nil, fmt.Errorf("unexpected git ls-tree output: %s", line)
+		}
+
+		fields := strings.Fields(meta)
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("unexpected git ls-tree output: %s", line)
+		}
+		mode, objectType, object := fields[0], fields[1], fields[2]
+
+		switch {
+		case objectType == "tree":
+			treeFS.addDir(name)
+		case objectType == "blob" && mode == "120000":
+			target, err := runGit(repoPath, "cat-file", "blob", object)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read symlink %s: %w", name, err)
+			}
+			treeFS.addSymlink(name, string(target))
+		case objectType == "blob":
+			size, err := strconv.ParseInt(fields[3], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("unexpected size for %s: %w", name, err)
+			}
+
+			perm := fs.FileMode(0644)
+			if mode == "100755" {
+				perm = 0755
+			}
+
+			treeFS.addFile(name, perm, size, gitBlobContents(repoPath, object))
+		}
+		// Submodules are commits in another repository and are skipped
+	}
+
+	shortCommit := string(commit)
+	if len(shortCommit) > 12 {
+		shortCommit = shortCommit[:12]
+	}
+
+	return NewAppFromFS(fmt.Sprintf("%s@%s", repoPath, shortCommit), treeFS), nil
+}
+
+func gitBlobContents(repoPath, object string) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		return runGit(repoPath, "cat-file", "blob", object)
+	}
+}
+
+func runGit(repoPath string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"-C", repoPath}, args...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("%w: %s", err, message)
+		}
+		return nil, err
+	}
+
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,205 @@
+package app
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeTar(t *testing.T, compress bool) []byte {
+	var buf bytes.Buffer
+	var tw *tar.Writer
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(&buf)
+		tw = tar.NewWriter(gz)
+	} else {
+		tw = tar.NewWriter(&buf)
+	}
+
+	write := func(header *tar.Header, contents string) {
+		header.Size = int64(len(contents))
+		require.NoError(t, tw.WriteHeader(header))
+		_, err := tw.Write([]byte(contents))
+		require.NoError(t, err)
+	}
+
+	write(&tar.Header{Name: "./", Typeflag: tar.TypeDir, Mode: 0755}, "")
+	write(&tar.Header{Name: "./package.json", Typeflag: tar.TypeReg, Mode: 0644}, `{"name": "archive"}`)
+	write(&tar.Header{Name: "./src/index.ts", Typeflag: tar.TypeReg, Mode: 0644}, "console.log('hi')")
+	write(&tar.Header{Name: "./start.sh", Typeflag: tar.TypeReg, Mode: 0755}, "node index.js")
+	write(&tar.Header{Name: "./main.ts", Typeflag: tar.TypeSymlink, Linkname: "src/index.ts"}, "")
+	write(&tar.Header{Name: "./copy.json", Typeflag: tar.TypeLink, Linkname: "package.json"}, "")
+
+	require.NoError(t, tw.Close())
+	if gz != nil {
+		require.NoError(t, gz.Close())
+	}
+
+	return buf.Bytes()
+}
+
+func requireArchiveApp(t *testing.T, app *App) {
+	var packageJSON PackageJSON
+	require.NoError(t, app.ReadJSON("package.json", &packageJSON))
+	require.Equal(t, "archive", packageJSON.Name)
+
+	files, err := app.FindFiles("**/*.ts")
+	require.NoError(t, err)
+	require.Equal(t, []string{"main.ts", "src/index.ts"}, files)
+
+	dirs, err := app.FindDirectories("*")
+	require.NoError(t, err)
+	require.Equal(t, []string{"src"}, dirs)
+
+	content, err := app.ReadFile("main.ts")
+	require.NoError(t, err)
+	require.Equal(t, "console.log('hi')", content)
+
+	content, err = app.ReadFile("copy.json")
+	require.NoError(t, err)
+	require.Equal(t, `{"name": "archive"}`, content)
+
+	require.True(t, app.HasMatch("src"))
+	require.True(t, app.IsFileExecutable("start.sh"))
+	require.False(t, app.IsFileExecutable("package.json"))
+
+	_, ok := app.LocalDir()
+	require.False(t, ok)
+}
+
+func TestNewAppFromTar(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		archivePath := filepath.Join(t.TempDir(), "app.tar")
+		require.NoError(t, os.WriteFile(archivePath, writeTar(t, compress), 0644))
+
+		app, err := NewAppFromTar(archivePath)
+		require.NoError(t, err)
+		require.Equal(t, archivePath, app.Source)
+
+		requireArchiveApp(t, app)
+	}
+}
+
+func TestNewAppFromTarLargeFile(t *testing.T) {
+	large := strings.Repeat("a", maxCachedFileSize+1)
+
+	for _, compress := range []bool{false, true} {
+		var buf bytes.Buffer
+		var tw *tar.Writer
+		var gz *gzip.Writer
+		if compress {
+			gz = gzip.NewWriter(&buf)
+			tw = tar.NewWriter(gz)
+		} else {
+			tw = tar.NewWriter(&buf)
+		}
+		for name, contents := range map[string]string{"large.txt": large, "small.txt": "small"} {
+			require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(contents))}))
+			_, err := tw.Write([]byte(contents))
+			require.NoError(t, err)
+		}
+		require.NoError(t, tw.Close())
+		if gz != nil {
+			require.NoError(t, gz.Close())
+		}
+
+		// Archives on disk are read again when a large file is used
+		archivePath := filepath.Join(t.TempDir(), "app.tar")
+		require.NoError(t, os.WriteFile(archivePath, buf.Bytes(), 0644))
+		app, err := NewAppFromTar(archivePath)
+		require.NoError(t, err)
+
+		content, err := app.ReadFile("large.txt")
+		require.NoError(t, err)
+		require.Equal(t, large, content)
+
+		content, err = app.ReadFile("small.txt")
+		require.NoError(t, err)
+		require.Equal(t, "small", content)
+
+		// Streams only keep small files
+		app, err = NewAppFromTarReader("stream", bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		require.True(t, app.HasMatch("large.txt"))
+
+		_, err = app.ReadFile("large.txt")
+		require.ErrorContains(t, err, "can not be read from a stream")
+
+		content, err = app.ReadFile("small.txt")
+		require.NoError(t, err)
+		require.Equal(t, "small", content)
+	}
+}
+
+func TestNewAppLocalDir(t *testing.T) {
+	app, err := NewApp("../../examples/node-bun")
+	require.NoError(t, err)
+
+	dir, ok := app.LocalDir()
+	require.True(t, ok)
+	require.Equal(t, app.Source, dir)
+}
+
+func runGitCommand(t *testing.T, dir string, args ...string) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		"GIT_CONFIG_GLOBAL=/dev/null",
+	)
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+}
+
+func TestNewAppFromGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+	runGitCommand(t, repo, "init", "-q")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "src"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "package.json"), []byte(`{"name": "archive"}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "copy.json"), []byte(`{"name": "archive"}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "src/index.ts"), []byte("console.log('hi')"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "start.sh"), []byte("node index.js"), 0755))
+	require.NoError(t, os.Symlink("src/index.ts", filepath.Join(repo, "main.ts")))
+	runGitCommand(t, repo, "add", "-A")
+	runGitCommand(t, repo, "commit", "-q", "-m", "first")
+	runGitCommand(t, repo, "tag", "v1")
+
+	// Changes after the commit are not part of the app
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "package.json"), []byte(`{"name": "changed"}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "extra.ts"), []byte(""), 0644))
+	runGitCommand(t, repo, "add", "-A")
+	runGitCommand(t, repo, "commit", "-q", "-m", "second")
+
+	app, err := NewAppFromGit(repo, "v1")
+	require.NoError(t, err)
+	require.Contains(t, app.Source, repo+"@")
+	requireArchiveApp(t, app)
+
+	bare := filepath.Join(t.TempDir(), "app.git")
+	runGitCommand(t, repo, "clone", "-q", "--bare", repo, bare)
+
+	app, err = NewAppFromGit(bare, "v1")
+	require.NoError(t, err)
+	requireArchiveApp(t, app)
+
+	app, err = NewAppFromGit(bare, "")
+	require.NoError(t, err)
+	require.True(t, app.HasMatch("extra.ts"))
+
+	_, err = NewAppFromGit(repo, "missing")
+	require.Error(t, err)
+}
@@ -0,0 +1,241 @@
+package app
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// treeFS is a read only fs.FS built from a list of entries, such as the files of an archive or a git tree.
+// The contents of a file are loaded the first time it is read
+type treeFS struct {
+	entries map[string]*treeEntry
+}
+
+type treeEntry struct {
+	name     string
+	mode     fs.FileMode
+	size     int64
+	children []string
+
+	// target is the path a symlink points to, relative to the root of the tree
+	target string
+
+	load     func() ([]byte, error)
+	loadOnce sync.Once
+	data     []byte
+	err      error
+}
+
+func newTreeFS() *treeFS {
+	return &treeFS{
+		entries: map[string]*treeEntry{
+			".": {name: ".", mode: fs.ModeDir | 0755},
+		},
+	}
+}
+
+// addFile adds a file and its parent directories. A negative size is computed from the contents when stat is called
+func (t *treeFS) addFile(name string, mode fs.FileMode, size int64, load func() ([]byte, error)) {
+	t.add(name, &treeEntry{name: path.Base(name), mode: mode, size: size, load: load})
+}
+
+// addSymlink adds a symlink. Links are followed when they point to another entry in the tree
+func (t *treeFS) addSymlink(name, linkname string) {
+	target := path.Join(path.Dir(name), linkname)
+	if path.IsAbs(linkname) {
+		target = strings.TrimPrefix(path.Clean(linkname), "/")
+	}
+
+	t.add(name, &treeEntry{name: path.Base(name), mode: fs.ModeSymlink | 0777, target: target})
+}
+
+// addDir adds a directory and its parents
+func (t *treeFS) addDir(name string) {
+	if _, ok := t.entries[name]; ok || name == "." {
+		return
+	}
+
+	t.add(name, &treeEntry{name: path.Base(name), mode: fs.ModeDir | 0755})
+}
+
+func (t *treeFS) add(name string, entry *treeEntry) {
+	parent := path.Dir(name)
+	t.addDir(parent)
+
+	if _, ok := t.entries[name]; !ok {
+		parentEntry := t.entries[parent]
+		parentEntry.children = append(parentEntry.children, entry.name)
+	}
+	t.entries[name] = entry
+}
+
+// resolve finds the entry for a path, following symlinks
+func (t *treeFS) resolve(op, name string) (*treeEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	entry, ok := t.entries[name]
+	for hops := 0; ok && entry.mode&fs.ModeSymlink != 0; hops++ {
+		if hops > 40 {
+			return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+		}
+		entry, ok = t.entries[entry.target]
+	}
+
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+
+	return entry, nil
+}
+
+func (t *treeFS) Open(name string) (fs.File, error) {
+	entry, err := t.resolve("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.mode.IsDir() {
+		return &treeDir{fs: t, path: name, entry: entry}, nil
+	}
+
+	data, err := entry.contents()
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	return &treeFile{entry: entry, reader: bytes.NewReader(data)}, nil
+}
+
+func (t *treeFS) Stat(name string) (fs.FileInfo, error) {
+	entry, err := t.resolve("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return entry.info(), nil
+}
+
+func (t *treeFS) ReadFile(name string) ([]byte, error) {
+	entry, err := t.resolve("read", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.mode.IsDir() {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+
+	data, err := entry.contents()
+	if err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+
+	return slices.Clone(data), nil
+}
+
+func (t *treeFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entry, err := t.resolve("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !entry.mode.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	return t.dirEntries(name, entry), nil
+}
+
+func (t *treeFS) dirEntries(name string, entry *treeEntry) []fs.DirEntry {
+	children := slices.Sorted(slices.Values(entry.children))
+
+	entries := make([]fs.DirEntry, 0, len(children))
+	for _, child := range children {
+		childEntry := t.entries[path.Join(name, child)]
+		entries = append(entries, fs.FileInfoToDirEntry(childEntry.info()))
+	}
+
+	return entries
+}
+
+func (e *treeEntry) contents() ([]byte, error) {
+	e.loadOnce.Do(func() {
+		if e.load != nil {
+			e.data, e.err = e.load()
+		}
+	})
+	return e.data, e.err
+}
+
+func (e *treeEntry) info() fs.FileInfo {
+	size := e.size
+	if size < 0 {
+		data, _ := e.contents()
+		size = int64(len(data))
+	}
+	return &treeFileInfo{name: e.name, mode: e.mode, size: size}
+}
+
+type treeFileInfo struct {
+	name string
+	mode fs.FileMode
+	size int64
+}
+
+func (i *treeFileInfo) Name() string       { return i.name }
+func (i *treeFileInfo) Size() int64        { return i.size }
+func (i *treeFileInfo) Mode() fs.FileMode  { return i.mode }
+func (i *treeFileInfo) ModTime() time.Time { return time.Time{} }
+func (i *treeFileInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i *treeFileInfo) Sys() any           { return nil }
+
+type treeFile struct {
+	entry  *treeEntry
+	reader *bytes.Reader
+}
+
+func (f *treeFile) Stat() (fs.FileInfo, error) { return f.entry.info(), nil }
+func (f *treeFile) Read(b []byte) (int, error) { return f.reader.Read(b) }
+func (f *treeFile) Close() error               { return nil }
+
+type treeDir struct {
+	fs      *treeFS
+	path    string
+	entry   *treeEntry
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *treeDir) Stat() (fs.FileInfo, error) { return d.entry.info(), nil }
+func (d *treeDir) Close() error               { return nil }
+
+func (d *treeDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.path, Err: fs.ErrInvalid}
+}
+
+func (d *treeDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if d.entries == nil {
+		d.entries = d.fs.dirEntries(d.path, d.entry)
+	}
+
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+
+	n = min(n, len(remaining))
+	d.offset += n
+	return remaining[:n], nil
+}
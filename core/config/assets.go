@@ -0,0 +1,100 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// AssetFile is an asset whose contents are read from a file in the app and rendered as a template
+type AssetFile struct {
+	File string `json:"file" jsonschema:"description=Path to a file in the app. The contents are rendered with Go templates"`
+}
+
+// splitAssets separates the inline assets of a step from the assets that reference a file in the app.
+// The returned data has only the inline assets so that it can be unmarshalled into a plan step
+func splitAssets(data []byte) ([]byte, map[string]*AssetFile, error) {
+	raw := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, nil, err
+	}
+
+	rawAssets, ok := raw["assets"]
+	if !ok {
+		return data, nil, nil
+	}
+
+	assets := map[string]json.RawMessage{}
+	if err := json.Unmarshal(rawAssets, &assets); err != nil {
+		return nil, nil, err
+	}
+
+	var assetFiles map[string]*AssetFile
+	for _, name := range slices.Sorted(maps.Keys(assets)) {
+		var assetFile AssetFile
+		if err := json.Unmarshal(assets[name], &assetFile); err != nil {
+			continue
+		}
+
+		// An asset without a file would have no contents
+		if strings.TrimSpace(assetFile.File) == "" {
+			return nil, nil, fmt.Errorf("asset `%s` has no `file`. Use a string for inline contents, or set `file` to the path of a file in the app", name)
+		}
+
+		if assetFiles == nil {
+			assetFiles = map[string]*AssetFile{}
+		}
+		assetFiles[name] = &assetFile
+		delete(assets, name)
+	}
+
+	if assetFiles == nil {
+		return data, nil, nil
+	}
+
+	inlineAssets, err := json.Marshal(assets)
+	if err != nil {
+		return nil, nil, err
+	}
+	raw["assets"] = inlineAssets
+
+	data, err = json.Marshal(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, assetFiles, nil
+}
+
+func (s StepConfig) JSONSchemaExtend(schema *jsonschema.Schema) {
+	s.Step.JSONSchemaExtend(schema)
+
+	// Assets can be inline contents or a reference to a file in the app
+	fileSchema := &jsonschema.Schema{
+		Type:       "object",
+		Properties: jsonschema.NewProperties(),
+	}
+	fileSchema.Properties.Set("file", &jsonschema.Schema{
+		Type:        "string",
+		Description: "Path to a file in the app. The contents are rendered with Go templates",
+	})
+	fileSchema.Required = []string{"file"}
+
+	schema.Properties.Set("assets", &jsonschema.Schema{
+		Type:        "object",
+		Description: "The assets available to this step. The key is the name of the asset that is referenced in a file command",
+		AdditionalProperties: &jsonschema.Schema{
+			OneOf: []*jsonschema.Schema{
+				{
+					Type:        "string",
+					Description: "The contents of the asset",
+				},
+				fileSchema,
+			},
+		},
+	})
+}
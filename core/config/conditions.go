@@ -0,0 +1,94 @@
+package config
+
+import (
+	"encoding/json"
+
+	"github.com/unbindapp/railpack/core/plan"
+)
+
+// When describes the conditions under which a step or override is applied.
+// All of the conditions that are set must match.
+type When struct {
+	Env      map[string]string `json:"env,omitempty" jsonschema:"description=Environment variables that must be set to the given values"`
+	Provider string            `json:"provider,omitempty" jsonschema:"description=The name of the provider that must be used for the build (e.g. node)"`
+	File     string            `json:"file,omitempty" jsonschema:"description=A file or glob pattern that must exist in the app"`
+}
+
+// StepConfig is a step definition in the config with an optional condition
+type StepConfig struct {
+	plan.Step
+	When      *When    `json:"when,omitempty" jsonschema:"description=Only apply this step when all of the conditions match"`
+	BuildArgs []string `json:"buildArgs,omitempty" jsonschema:"description=The build args that this step uses. Use * for all build args. Provider install and build steps use all build args by default, other steps none"`
+
+	// Assets that are read from files in the app. These are defined in the assets field of the step
+	AssetFiles map[string]*AssetFile `json:"-"`
+}
+
+// Override is a partial config that is merged on top of the config when its conditions match
+type Override struct {
+	When      *When                  `json:"when" jsonschema:"description=Only apply this override when all of the conditions match"`
+	Steps     map[string]*StepConfig `json:"steps,omitempty" jsonschema:"description=Map of step names to step definitions"`
+	Deploy    *DeployConfig          `json:"deploy,omitempty" jsonschema:"description=Deploy configuration"`
+	Packages  map[string]string      `json:"packages,omitempty" jsonschema:"description=Map of package name to package version. A name can start with the apt: github: url: pip: or npm: backend"`
+	Caches    map[string]*plan.Cache `json:"caches,omitempty" jsonschema:"description=Map of cache name to cache definitions. The cache key can be referenced in an exec command"`
+	Secrets   []string               `json:"secrets,omitempty" jsonschema:"description=Secrets that should be made available to commands that have useSecrets set to true"`
+	BuildArgs map[string]string      `json:"buildArgs,omitempty" jsonschema:"description=Map of build arg name to value"`
+}
+
+// ConditionMatcher reports whether a condition matches the current build
+type ConditionMatcher func(when *When) bool
+
+func NewStepConfig(name string) *StepConfig {
+	return &StepConfig{Step: *plan.NewStep(name)}
+}
+
+func (s *StepConfig) UnmarshalJSON(data []byte) error {
+	data, assetFiles, err := splitAssets(data)
+	if err != nil {
+		return err
+	}
+	s.AssetFiles = assetFiles
+
+	if err := s.Step.UnmarshalJSON(data); err != nil {
+		return err
+	}
+
+	aux := struct {
+		When      *When    `json:"when"`
+		BuildArgs []string `json:"buildArgs"`
+	}{}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	s.When = aux.When
+	s.BuildArgs = aux.BuildArgs
+
+	return nil
+}
+
+// ApplyOverrides merges all overrides whose conditions match into a new config.
+// Overrides are applied in the order they are defined.
+func (c *Config) ApplyOverrides(matches ConditionMatcher) *Config {
+	configs := []*Config{c}
+
+	for _, override := range c.Overrides {
+		if override.When != nil && !matches(override.When) {
+			continue
+		}
+
+		configs = append(configs, &Config{
+			Steps:     override.Steps,
+			Deploy:    override.Deploy,
+			Packages:  override.Packages,
+			Caches:    override.Caches,
+			Secrets:   override.Secrets,
+			BuildArgs: override.BuildArgs,
+		})
+	}
+
+	if len(configs) == 1 {
+		return c
+	}
+
+	return Merge(configs...)
+}
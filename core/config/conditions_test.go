@@ -0,0 +1,106 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/unbindapp/railpack/core/plan"
+)
+
+func TestStepConfigUnmarshalWhen(t *testing.T) {
+	configJSON := `{
+		"steps": {
+			"seed": {
+				"commands": ["npm run seed"],
+				"when": {
+					"env": { "RAILPACK_ENV": "staging" },
+					"provider": "node",
+					"file": "prisma/schema.prisma"
+				}
+			}
+		}
+	}`
+
+	config := EmptyConfig()
+	require.NoError(t, json.Unmarshal([]byte(configJSON), config))
+
+	seed := config.Steps["seed"]
+	require.Equal(t, plan.ShellCommandString("npm run seed"), seed.Commands[0].(plan.ExecCommand).Cmd)
+	require.Equal(t, &When{
+		Env:      map[string]string{"RAILPACK_ENV": "staging"},
+		Provider: "node",
+		File:     "prisma/schema.prisma",
+	}, seed.When)
+}
+
+func TestApplyOverrides(t *testing.T) {
+	configJSON := `{
+		"packages": { "node": "20" },
+		"deploy": { "startCommand": "node server.js" },
+		"overrides": [
+			{
+				"when": { "env": { "RAILPACK_ENV": "production" } },
+				"packages": { "node": "22" },
+				"deploy": { "startCommand": "node server.js --production" }
+			},
+			{
+				"when": { "provider": "python" },
+				"packages": { "python": "3.12" }
+			},
+			{
+				"when": { "env": { "RAILPACK_ENV": "production" } },
+				"secrets": ["SENTRY_TOKEN"]
+			}
+		]
+	}`
+
+	config := EmptyConfig()
+	require.NoError(t, json.Unmarshal([]byte(configJSON), config))
+
+	matches := func(when *When) bool {
+		return when.Env["RAILPACK_ENV"] == "production"
+	}
+
+	applied := config.ApplyOverrides(matches)
+	require.Equal(t, map[string]string{"node": "22"}, applied.Packages)
+	require.Equal(t, "node server.js --production", applied.Deploy.StartCmd)
+	require.Equal(t, []string{"SENTRY_TOKEN"}, applied.Secrets)
+
+	// The original config is left untouched
+	require.Equal(t, map[string]string{"node": "20"}, config.Packages)
+	require.Equal(t, "node server.js", config.Deploy.StartCmd)
+
+	none := config.ApplyOverrides(func(*When) bool { return false })
+	require.Same(t, config, none)
+}
+
+func TestStepConfigUnmarshalAssetFiles(t *testing.T) {
+	configJSON := `{
+		"steps": {
+			"build": {
+				"assets": {
+					"inline.txt": "hello",
+					"nginx.conf": { "file": "deploy/nginx.conf" }
+				}
+			}
+		}
+	}`
+
+	config := EmptyConfig()
+	require.NoError(t, json.Unmarshal([]byte(configJSON), config))
+
+	build := config.Steps["build"]
+	require.Equal(t, map[string]string{"inline.txt": "hello"}, build.Assets)
+	require.Equal(t, map[string]*AssetFile{"nginx.conf": {File: "deploy/nginx.conf"}}, build.AssetFiles)
+}
+
+func TestStepConfigUnmarshalAssetFilesWithoutFile(t *testing.T) {
+	for _, asset := range []string{`{}`, `{"file": ""}`} {
+		configJSON := `{"steps": {"build": {"assets": {"nginx.conf": ` + asset + `}}}}`
+
+		config := EmptyConfig()
+		err := json.Unmarshal([]byte(configJSON), config)
+		require.ErrorContains(t, err, "asset `nginx.conf` has no `file`", asset)
+	}
+}
@@ -14,31 +14,35 @@ type DeployConfig struct {
 	AptPackages []string          `json:"aptPackages,omitempty" jsonschema:"description=List of apt packages to include at runtime"`
 	Inputs      []plan.Input      `json:"inputs,omitempty" jsonschema:"description=The inputs for the deploy step"`
 	StartCmd    string            `json:"startCommand,omitempty" jsonschema:"description=The command to run in the container"`
+	ReleaseCmd  string            `json:"releaseCommand,omitempty" jsonschema:"description=The command to run once per release before the new container starts receiving traffic (e.g. database migrations)"`
 	Variables   map[string]string `json:"variables,omitempty" jsonschema:"description=The variables available to this step. The key is the name of the variable that is referenced in a variable command"`
 	Paths       []string          `json:"paths,omitempty" jsonschema:"description=The paths to prepend to the $PATH environment variable"`
 }
 
 type Config struct {
-	Provider         *string                `json:"provider" jsonschema:"description=The provider to use"`
+	Provider         ProviderList           `json:"provider" jsonschema:"description=The provider to use"`
 	BuildAptPackages []string               `json:"buildAptPackages,omitempty" jsonschema:"description=List of apt packages to install during the build step"`
-	Steps            map[string]*plan.Step  `json:"steps,omitempty" jsonschema:"description=Map of step names to step definitions"`
+	Steps            map[string]*StepConfig `json:"steps,omitempty" jsonschema:"description=Map of step names to step definitions"`
 	Deploy           *DeployConfig          `json:"deploy,omitempty" jsonschema:"description=Deploy configuration"`
-	Packages         map[string]string      `json:"packages,omitempty" jsonschema:"description=Map of package name to package version"`
+	Packages         map[string]string      `json:"packages,omitempty" jsonschema:"description=Map of package name to package version. A name can start with the apt: github: url: pip: or npm: backend"`
 	Caches           map[string]*plan.Cache `json:"caches,omitempty" jsonschema:"description=Map of cache name to cache definitions. The cache key can be referenced in an exec command"`
 	Secrets          []string               `json:"secrets,omitempty" jsonschema:"description=Secrets that should be made available to commands that have useSecrets set to true"`
+	BuildArgs        map[string]string      `json:"buildArgs,omitempty" jsonschema:"description=Map of build arg name to value. Build args are plain variables of the steps that use them and are visible in the build plan"`
+	Overrides        []Override             `json:"overrides,omitempty" jsonschema:"description=Partial configs that are merged on top of this config when their conditions match"`
+	Plugins          []string               `json:"plugins,omitempty" jsonschema:"description=Paths to YAML provider plugins or directories of them in the app"`
 }
 
 func EmptyConfig() *Config {
 	return &Config{
-		Steps:    make(map[string]*plan.Step),
+		Steps:    make(map[string]*StepConfig),
 		Packages: make(map[string]string),
 		Caches:   make(map[string]*plan.Cache),
 		Deploy:   &DeployConfig{},
 	}
 }
 
-func (c *Config) GetOrCreateStep(name string) *plan.Step {
-	step := plan.NewStep(name)
+func (c *Config) GetOrCreateStep(name string) *StepConfig {
+	step := NewStepConfig(name)
 	if existingStep, exists := c.Steps[name]; exists {
 		step = existingStep
 	}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/unbindapp/railpack/core/plan"
+)
+
+// Matches ${VAR} and ${VAR:-default}
+var interpolationRe = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// Railpack config variables are already written into the plan (e.g. RAILPACK_BUILD_CMD),
+// so they are never treated as secrets when interpolating
+const configVariablePrefix = "RAILPACK_"
+
+type interpolator struct {
+	variables map[string]string
+	secrets   []string
+}
+
+// Interpolate resolves ${VAR} and ${VAR:-default} references in the step commands, step variables,
+// deploy commands, and deploy variables of the config and its overrides against the given variables.
+//
+// Secret values are never written into the config. References to secrets in commands are left
+// untouched so that they are resolved by the shell at runtime, and references to secrets in
+// variables are refused since those are never evaluated by a shell.
+func (c *Config) Interpolate(variables map[string]string, secrets []string) error {
+	i := &interpolator{
+		variables: variables,
+		secrets:   slices.Clone(secrets),
+	}
+
+	// Secrets added by any override are never interpolated, even if the override does not match
+	for _, override := range c.Overrides {
+		i.secrets = append(i.secrets, override.Secrets...)
+	}
+
+	if err := i.interpolateSteps(c.Steps, "steps"); err != nil {
+		return err
+	}
+
+	if err := i.interpolateDeploy(c.Deploy, "deploy"); err != nil {
+		return err
+	}
+
+	for idx, override := range c.Overrides {
+		if err := i.interpolateSteps(override.Steps, fmt.Sprintf("overrides.%d.steps", idx)); err != nil {
+			return err
+		}
+
+		if err := i.interpolateDeploy(override.Deploy, fmt.Sprintf("overrides.%d.deploy", idx)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (i *interpolator) interpolateSteps(steps map[string]*StepConfig, field string) error {
+	for _, name := range slices.Sorted(maps.Keys(steps)) {
+		step := steps[name]
+		if step == nil {
+			continue
+		}
+
+		for idx, cmd := range step.Commands {
+			if execCmd, ok := cmd.(plan.ExecCommand); ok {
+				execCmd.Cmd = i.interpolateCommand(execCmd.Cmd)
+				step.Commands[idx] = execCmd
+			}
+		}
+
+		if err := i.interpolateVariables(step.Variables, fmt.Sprintf("%s.%s.variables", field, name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (i *interpolator) interpolateDeploy(deploy *DeployConfig, field string) error {
+	if deploy == nil {
+		return nil
+	}
+
+	deploy.StartCmd = i.interpolateCommand(deploy.StartCmd)
+	deploy.ReleaseCmd = i.interpolateCommand(deploy.ReleaseCmd)
+
+	return i.interpolateVariables(deploy.Variables, field+".variables")
+}
+
+func (i *interpolator) isSecret(name string) bool {
+	return slices.Contains(i.secrets, name) && !strings.HasPrefix(name, configVariablePrefix)
+}
+
+// interpolateCommand replaces known, non-secret variables in a shell command.
+// Everything else is left as is so that the shell can resolve it at runtime (e.g. ${PORT:-8000})
+func (i *interpolator) interpolateCommand(cmd string) string {
+	return interpolationRe.ReplaceAllStringFunc(cmd, func(match string) string {
+		name := interpolationRe.FindStringSubmatch(match)[1]
+		if i.isSecret(name) {
+			return match
+		}
+
+		if value, ok := i.variables[name]; ok {
+			return value
+		}
+
+		return match
+	})
+}
+
+// interpolateVariables replaces all references in variable values.
+// Variables are not evaluated by a shell, so unknown variables resolve to their default (or empty)
+func (i *interpolator) interpolateVariables(variables map[string]string, field string) error {
+	for _, key := range slices.Sorted(maps.Keys(variables)) {
+		var err error
+
+		variables[key] = interpolationRe.ReplaceAllStringFunc(variables[key], func(match string) string {
+			parts := interpolationRe.FindStringSubmatch(match)
+			name, defaultValue := parts[1], parts[2]
+
+			if i.isSecret(name) {
+				err = fmt.Errorf("%s.%s references secret `%s`. Secrets cannot be interpolated into variables", field, key, name)
+				return match
+			}
+
+			if value, ok := i.variables[name]; ok {
+				return value
+			}
+
+			return defaultValue
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/unbindapp/railpack/core/plan"
+)
+
+func TestInterpolate(t *testing.T) {
+	configJSON := `{
+		"steps": {
+			"build": {
+				"commands": [
+					"npm run build:${RAILPACK_ENV}",
+					"echo ${API_TOKEN} ${UNKNOWN:-fallback}"
+				],
+				"variables": {
+					"TARGET": "${RAILPACK_ENV:-development}",
+					"REGION": "${REGION:-us-east}"
+				}
+			}
+		},
+		"deploy": {
+			"startCommand": "node server.js --env ${RAILPACK_ENV} --port ${PORT:-3000}",
+			"variables": {
+				"APP_ENV": "${RAILPACK_ENV}",
+				"LOG_LEVEL": "${LOG_LEVEL:-info}",
+				"MISSING": "${MISSING}"
+			}
+		}
+	}`
+
+	config := EmptyConfig()
+	require.NoError(t, json.Unmarshal([]byte(configJSON), config))
+
+	variables := map[string]string{
+		"RAILPACK_ENV": "production",
+		"API_TOKEN":    "super-secret",
+	}
+	secrets := []string{"RAILPACK_ENV", "API_TOKEN"}
+
+	require.NoError(t, config.Interpolate(variables, secrets))
+
+	build := config.Steps["build"]
+	require.Equal(t, plan.ShellCommandString("npm run build:production"), build.Commands[0].(plan.ExecCommand).Cmd)
+	require.Equal(t, plan.ShellCommandString("echo ${API_TOKEN} ${UNKNOWN:-fallback}"), build.Commands[1].(plan.ExecCommand).Cmd)
+	require.Equal(t, "production", build.Variables["TARGET"])
+	require.Equal(t, "us-east", build.Variables["REGION"])
+
+	require.Equal(t, "node server.js --env production --port ${PORT:-3000}", config.Deploy.StartCmd)
+	require.Equal(t, "production", config.Deploy.Variables["APP_ENV"])
+	require.Equal(t, "info", config.Deploy.Variables["LOG_LEVEL"])
+	require.Equal(t, "", config.Deploy.Variables["MISSING"])
+}
+
+func TestInterpolateRefusesSecretsInVariables(t *testing.T) {
+	config := EmptyConfig()
+	config.Deploy.Variables = map[string]string{
+		"TOKEN": "${API_TOKEN}",
+	}
+
+	err := config.Interpolate(map[string]string{"API_TOKEN": "super-secret"}, []string{"API_TOKEN"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "deploy.variables.TOKEN references secret `API_TOKEN`")
+	require.NotContains(t, err.Error(), "super-secret")
+	require.Equal(t, "${API_TOKEN}", config.Deploy.Variables["TOKEN"])
+}
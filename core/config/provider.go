@@ -0,0 +1,61 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/invopop/jsonschema"
+)
+
+// ProviderList is the list of providers to use. The first provider is the primary provider that
+// plans the deploy and the rest are auxiliary providers that contribute their build outputs.
+// In the config it is either the name of a single provider or a list of names
+type ProviderList []string
+
+// Primary returns the name of the primary provider
+func (p ProviderList) Primary() string {
+	if len(p) == 0 {
+		return ""
+	}
+	return p[0]
+}
+
+// Auxiliary returns the names of the auxiliary providers
+func (p ProviderList) Auxiliary() []string {
+	if len(p) < 2 {
+		return nil
+	}
+	return p[1:]
+}
+
+func (p *ProviderList) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		*p = nil
+		return nil
+	}
+
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*p = ProviderList{name}
+		return nil
+	}
+
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return fmt.Errorf("provider must be the name of a provider or a list of names: %w", err)
+	}
+	*p = names
+
+	return nil
+}
+
+func (ProviderList) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Description: "The provider to use. Use a list to add auxiliary providers (e.g. [\"python\", \"node\"]). The first provider is the primary provider",
+		OneOf: []*jsonschema.Schema{
+			{Type: "string"},
+			{Type: "array", Items: &jsonschema.Schema{Type: "string"}},
+		},
+	}
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviderListUnmarshal(t *testing.T) {
+	tests := []struct {
+		name      string
+		json      string
+		expected  ProviderList
+		primary   string
+		auxiliary []string
+	}{
+		{name: "missing", json: `{}`, expected: nil},
+		{name: "null", json: `{"provider": null}`, expected: nil},
+		{name: "single", json: `{"provider": "node"}`, expected: ProviderList{"node"}, primary: "node"},
+		{
+			name:      "list",
+			json:      `{"provider": ["python", "node"]}`,
+			expected:  ProviderList{"python", "node"},
+			primary:   "python",
+			auxiliary: []string{"node"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var config Config
+			require.NoError(t, json.Unmarshal([]byte(tt.json), &config))
+			require.Equal(t, tt.expected, config.Provider)
+			require.Equal(t, tt.primary, config.Provider.Primary())
+			require.Equal(t, tt.auxiliary, config.Provider.Auxiliary())
+		})
+	}
+
+	var config Config
+	require.Error(t, json.Unmarshal([]byte(`{"provider": 1}`), &config))
+}
+
+func TestMergeProviderList(t *testing.T) {
+	merged := Merge(
+		&Config{Provider: ProviderList{"python", "node"}},
+		&Config{},
+	)
+	require.Equal(t, ProviderList{"python", "node"}, merged.Provider)
+
+	merged = Merge(
+		&Config{Provider: ProviderList{"python", "node"}},
+		&Config{Provider: ProviderList{"golang"}},
+	)
+	require.Equal(t, ProviderList{"golang"}, merged.Provider)
+}
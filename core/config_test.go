@@ -34,6 +34,7 @@ func TestGenerateConfigFromEnvironment(t *testing.T) {
 				"RAILPACK_INSTALL_CMD":         "npm install",
 				"RAILPACK_BUILD_CMD":           "npm run build",
 				"RAILPACK_START_CMD":           "npm start",
+				"RAILPACK_RELEASE_CMD":         "npm run migrate",
 				"RAILPACK_PACKAGES":            "node@18 python@3.9",
 				"RAILPACK_BUILD_APT_PACKAGES":  "build-essential libssl-dev",
 				"RAILPACK_DEPLOY_APT_PACKAGES": "libssl-dev",
@@ -69,10 +70,11 @@ func TestGenerateConfigFromEnvironment(t *testing.T) {
 				"caches": {},
 				"deploy": {
 					"startCommand": "npm start",
+					"releaseCommand": "npm run migrate",
 					"aptPackages": ["libssl-dev"]
 				},
 				"secrets": ["RAILPACK_BUILD_APT_PACKAGES", "RAILPACK_BUILD_CMD", "RAILPACK_DEPLOY_APT_PACKAGES",
-					"RAILPACK_INSTALL_CMD", "RAILPACK_PACKAGES", "RAILPACK_START_CMD"]
+					"RAILPACK_INSTALL_CMD", "RAILPACK_PACKAGES", "RAILPACK_RELEASE_CMD", "RAILPACK_START_CMD"]
 			}`,
 		},
 	}
@@ -92,3 +94,13 @@ func TestGenerateConfigFromEnvironment(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateConfigFromEnvironmentBuildArgs(t *testing.T) {
+	env := app.NewEnvironment(&map[string]string{"API_TOKEN": "secret"})
+	env.AddBuildArgs([]string{"NODE_ENV=production"})
+
+	gotConfig := GenerateConfigFromEnvironment(env)
+
+	require.Equal(t, map[string]string{"NODE_ENV": "production"}, gotConfig.BuildArgs)
+	require.Equal(t, []string{"API_TOKEN"}, gotConfig.Secrets)
+}
@@ -1,17 +1,25 @@
 package core
 
 import (
+	"cmp"
+	"errors"
+	"fmt"
 	"maps"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/unbindapp/railpack/core/app"
 	c "github.com/unbindapp/railpack/core/config"
+	"github.com/unbindapp/railpack/core/eol"
 	"github.com/unbindapp/railpack/core/generate"
+	"github.com/unbindapp/railpack/core/lockfile"
 	"github.com/unbindapp/railpack/core/logger"
+	"github.com/unbindapp/railpack/core/mise"
 	"github.com/unbindapp/railpack/core/plan"
 	"github.com/unbindapp/railpack/core/providers"
+	"github.com/unbindapp/railpack/core/providers/plugin"
 	"github.com/unbindapp/railpack/core/providers/procfile"
 	"github.com/unbindapp/railpack/core/resolver"
 	"github.com/unbindapp/railpack/internal/utils"
@@ -25,21 +33,58 @@ type GenerateBuildPlanOptions struct {
 	RailpackVersion          string
 	BuildCommand             string
 	StartCommand             string
+	ReleaseCommand           string
 	PreviousVersions         map[string]string
 	ConfigFilePath           string
 	ErrorMissingStartCommand bool
+
+	// Directories or files with provider plugins. These can include exec plugins
+	PluginPaths []string
+
+	// FrozenLockfile fails the plan if the packages or images differ from the railpack.lock of the app
+	FrozenLockfile bool
+
+	// IgnoreLockfile resolves all versions again instead of using the railpack.lock of the app
+	IgnoreLockfile bool
+
+	// VersionSources are where versions are resolved from instead of mise. See resolver.ParseVersionSources
+	VersionSources []string
+
+	// VersionCacheTTL is how long the versions listed by mise are cached on disk. Versions are not cached when it is zero
+	VersionCacheTTL time.Duration
+
+	// RefreshVersions lists the versions with mise again instead of using the cache
+	RefreshVersions bool
+
+	// EOLRulesPath is a file with EOL rules that are merged into the embedded ones. See eol.Load
+	EOLRulesPath string
+
+	// FailOnEOL fails the plan instead of warning when a resolved package is end-of-life or has known vulnerabilities
+	FailOnEOL bool
 }
 
 type BuildResult struct {
 	RailpackVersion   string                               `json:"railpackVersion,omitempty"`
 	Plan              *plan.BuildPlan                      `json:"plan,omitempty"`
+	ReleaseCommand    string                               `json:"releaseCommand,omitempty"`
 	ResolvedPackages  map[string]*resolver.ResolvedPackage `json:"resolvedPackages,omitempty"`
 	Metadata          map[string]string                    `json:"metadata,omitempty"`
-	DetectedProviders []string                             `json:"detectedProviders,omitempty"`
+	DetectedProviders []*DetectedProvider                  `json:"detectedProviders,omitempty"`
 	Logs              []logger.Msg                         `json:"logs,omitempty"`
 	Success           bool                                 `json:"success,omitempty"`
 }
 
+// DetectedProvider is a provider that matched the app, along with the evidence it found
+type DetectedProvider struct {
+	Name     string   `json:"name"`
+	Score    int      `json:"score"`
+	Reasons  []string `json:"reasons,omitempty"`
+	Selected bool     `json:"selected,omitempty"`
+
+	// Auxiliary is set when the provider builds part of the app alongside the selected provider
+	Auxiliary bool `json:"auxiliary,omitempty"`
+}
+
 func GenerateBuildPlan(app *app.App, env *app.Environment, options *GenerateBuildPlanOptions) *BuildResult {
 	logger := logger.NewLogger()
 
@@ -63,9 +108,47 @@ func GenerateBuildPlan(app *app.App, env *app.Environment, options *GenerateBuil
 		}
 	}
 
+	versionSource, err := resolver.ParseVersionSources(options.VersionSources, mise.InstallDir, resolver.VersionCacheOptions{
+		TTL:     options.VersionCacheTTL,
+		Refresh: options.RefreshVersions,
+	})
+	if err != nil {
+		logger.LogError("%s", err.Error())
+		return &BuildResult{Success: false, Logs: logger.Logs}
+	}
+
+	ctx.Resolver.SetVersionSource(versionSource)
+
+	lock, err := readLockfile(app, options)
+	if err != nil {
+		logger.LogError("%s", err.Error())
+		return &BuildResult{Success: false, Logs: logger.Logs}
+	}
+
+	if lock != nil {
+		ctx.Resolver.SetLockedPackages(lock.Packages, options.FrozenLockfile)
+	}
+
 	// Figure out what providers to use
-	providerToUse, detectedProviderName := getProviders(ctx, config)
-	ctx.Metadata.Set("providers", detectedProviderName)
+	allProviders, err := getAllProviders(app, config, options)
+	if err != nil {
+		logger.LogError("%s", err.Error())
+		return &BuildResult{Success: false, Logs: logger.Logs}
+	}
+
+	providerToUse, detectedProviders := getProviders(ctx, config, allProviders)
+	if len(detectedProviders) > 0 {
+		ctx.Metadata.Set("providers", detectedProviders[0].Name)
+	}
+
+	if providerToUse != nil {
+		ctx.ProviderName = providerToUse.Name()
+	}
+
+	auxiliaryProviders := getAuxiliaryProviders(ctx, config, allProviders, providerToUse, detectedProviders)
+	for _, auxiliary := range auxiliaryProviders {
+		ctx.AuxiliaryProviderNames = append(ctx.AuxiliaryProviderNames, auxiliary.Name())
+	}
 
 	// TODO: We should indicate if we have packages specified in the config
 	// so that providers can determine if they should include mise in the final image (e.g. for shell script)
@@ -74,36 +157,67 @@ func GenerateBuildPlan(app *app.App, env *app.Environment, options *GenerateBuil
 		err = providerToUse.Plan(ctx)
 		if err != nil {
 			logger.LogError("%s", err.Error())
-			return &BuildResult{Success: false, Logs: logger.Logs}
+			return &BuildResult{Success: false, DetectedProviders: detectedProviders, Logs: logger.Logs}
 		}
 	}
 
+	// Auxiliary providers add their steps under a sub context and their outputs are merged into the deploy
+	for _, auxiliary := range auxiliaryProviders {
+		ctx.EnterSubContext(auxiliary.Name())
+		inputs, err := auxiliary.PlanAuxiliary(ctx)
+		ctx.ExitSubContext()
+
+		if err != nil {
+			logger.LogError("%s", err.Error())
+			return &BuildResult{Success: false, DetectedProviders: detectedProviders, Logs: logger.Logs}
+		}
+
+		ctx.Deploy.Inputs = append(ctx.Deploy.Inputs, inputs...)
+	}
+
 	// Run the procfile provider to support apps that have a Procfile with a start command
 	procfileProvider := &procfile.ProcfileProvider{}
 	if _, err := procfileProvider.Plan(ctx); err != nil {
 		logger.LogError("%s", err.Error())
-		return &BuildResult{Success: false, Logs: logger.Logs}
+		return &BuildResult{Success: false, DetectedProviders: detectedProviders, Logs: logger.Logs}
 	}
 
 	buildPlan, resolvedPackages, err := ctx.Generate()
 	if err != nil {
+		var driftErr *resolver.LockDriftError
+		if errors.As(err, &driftErr) {
+			err = fmt.Errorf("lockfile %s is out of date. Run `railpack lock` to update it\n%w", lockfile.FileName, err)
+		}
+
 		logger.LogError("%s", err.Error())
-		return &BuildResult{Success: false, Logs: logger.Logs}
+		return &BuildResult{Success: false, DetectedProviders: detectedProviders, Logs: logger.Logs}
+	}
+
+	if !checkEOL(resolvedPackages, logger, options) {
+		return &BuildResult{Success: false, DetectedProviders: detectedProviders, Logs: logger.Logs}
+	}
+
+	if lock != nil {
+		if err := lock.PinImages(buildPlan, options.FrozenLockfile); err != nil {
+			logger.LogError("lockfile %s is out of date. Run `railpack lock` to update it\n%s", lockfile.FileName, err.Error())
+			return &BuildResult{Success: false, DetectedProviders: detectedProviders, Logs: logger.Logs}
+		}
 	}
 
 	if !ValidatePlan(buildPlan, app, logger, &ValidatePlanOptions{
 		ErrorMissingStartCommand: options.ErrorMissingStartCommand,
 		ProviderToUse:            providerToUse,
 	}) {
-		return &BuildResult{Success: false, Logs: logger.Logs}
+		return &BuildResult{Success: false, DetectedProviders: detectedProviders, Logs: logger.Logs}
 	}
 
 	buildResult := &BuildResult{
 		RailpackVersion:   options.RailpackVersion,
 		Plan:              buildPlan,
+		ReleaseCommand:    buildPlan.Deploy.ReleaseCmd,
 		ResolvedPackages:  resolvedPackages,
 		Metadata:          ctx.Metadata.Properties,
-		DetectedProviders: []string{detectedProviderName},
+		DetectedProviders: detectedProviders,
 		Logs:              logger.Logs,
 		Success:           true,
 	}
@@ -111,6 +225,45 @@ func GenerateBuildPlan(app *app.App, env *app.Environment, options *GenerateBuil
 	return buildResult
 }
 
+// checkEOL warns about resolved packages that are end-of-life or have known vulnerabilities.
+// Returns false if they should fail the plan
+func checkEOL(resolvedPackages map[string]*resolver.ResolvedPackage, logger *logger.Logger, options *GenerateBuildPlanOptions) bool {
+	rules, err := eol.Load(options.EOLRulesPath)
+	if err != nil {
+		logger.LogError("%s", err.Error())
+		return false
+	}
+
+	findings := rules.Check(resolvedPackages, time.Now())
+	for _, finding := range findings {
+		if options.FailOnEOL {
+			logger.LogError("%s", finding.Message)
+		} else {
+			logger.LogWarn("%s", finding.Message)
+		}
+	}
+
+	return !options.FailOnEOL || len(findings) == 0
+}
+
+// readLockfile reads the railpack.lock of the app unless it is ignored. A frozen lockfile must exist
+func readLockfile(app *app.App, options *GenerateBuildPlanOptions) (*lockfile.Lockfile, error) {
+	if options.IgnoreLockfile {
+		return nil, nil
+	}
+
+	lock, err := lockfile.Read(app)
+	if err != nil {
+		return nil, err
+	}
+
+	if lock == nil && options.FrozenLockfile {
+		return nil, fmt.Errorf("a frozen lockfile was requested, but the app does not have a %s. Run `railpack lock` to create it", lockfile.FileName)
+	}
+
+	return lock, nil
+}
+
 // GetConfig merges the options, environment, and file config into a single config
 func GetConfig(app *app.App, env *app.Environment, options *GenerateBuildPlanOptions, logger *logger.Logger) (*c.Config, error) {
 	optionsConfig := GenerateConfigFromOptions(options)
@@ -124,6 +277,19 @@ func GetConfig(app *app.App, env *app.Environment, options *GenerateBuildPlanOpt
 
 	mergedConfig := c.Merge(optionsConfig, envConfig, fileConfig)
 
+	if env != nil {
+		// Build args are never secret, so they can always be interpolated
+		variables := maps.Clone(env.Variables)
+		maps.Copy(variables, mergedConfig.BuildArgs)
+
+		// Every --env variable is mounted as a secret, but only the secrets that are declared in the config are kept
+		// out of the interpolated config. Otherwise plain values like APP_ENV could never be interpolated
+		secrets := slices.Concat(optionsConfig.Secrets, fileConfig.Secrets)
+		if err := mergedConfig.Interpolate(variables, secrets); err != nil {
+			return nil, fmt.Errorf("failed to interpolate config: %w", err)
+		}
+	}
+
 	return mergedConfig, nil
 }
 
@@ -187,6 +353,10 @@ func GenerateConfigFromEnvironment(env *app.Environment) *c.Config {
 		config.Deploy.StartCmd = startCmdVar
 	}
 
+	if releaseCmdVar, _ := env.GetConfigVariable("RELEASE_CMD"); releaseCmdVar != "" {
+		config.Deploy.ReleaseCmd = releaseCmdVar
+	}
+
 	if envPackages, _ := env.GetConfigVariable("PACKAGES"); envPackages != "" {
 		config.Packages = utils.ParsePackageWithVersion(strings.Split(envPackages, " "))
 	}
@@ -200,6 +370,9 @@ func GenerateConfigFromEnvironment(env *app.Environment) *c.Config {
 	}
 
 	config.Secrets = append(config.Secrets, slices.Sorted(maps.Keys(env.Variables))...)
+	if len(env.BuildArgs) > 0 {
+		config.BuildArgs = maps.Clone(env.BuildArgs)
+	}
 
 	return config
 }
@@ -224,58 +397,168 @@ func GenerateConfigFromOptions(options *GenerateBuildPlanOptions) *c.Config {
 		config.Deploy.StartCmd = options.StartCommand
 	}
 
+	if options.ReleaseCommand != "" {
+		config.Deploy.ReleaseCmd = options.ReleaseCommand
+	}
+
 	return config
 }
 
-func getProviders(ctx *generate.GenerateContext, config *c.Config) (providers.Provider, string) {
-	allProviders := providers.GetLanguageProviders()
+// getAllProviders loads the plugins and returns them with the language providers.
+// Plugins in the config are YAML providers in the app. Plugin paths from the options can also be exec plugins
+func getAllProviders(app *app.App, config *c.Config, options *GenerateBuildPlanOptions) ([]providers.Provider, error) {
+	configPlugins, err := plugin.LoadFromApp(app, config.Plugins)
+	if err != nil {
+		return nil, err
+	}
 
-	var providerToUse providers.Provider
-	var detectedProvider string
+	optionPlugins, err := plugin.Load(options.PluginPaths, true)
+	if err != nil {
+		return nil, err
+	}
+
+	plugins := []providers.Provider{}
+	for _, p := range append(configPlugins, optionPlugins...) {
+		plugins = append(plugins, p)
+	}
+
+	return providers.WithPlugins(plugins), nil
+}
+
+func getProviders(ctx *generate.GenerateContext, config *c.Config, allProviders []providers.Provider) (providers.Provider, []*DetectedProvider) {
+	detectedProviders := []*DetectedProvider{}
+	providersByName := map[string]providers.Provider{}
 
 	// Even if there are providers manually specified, we want to detect to see what type of app this is
 	for _, provider := range allProviders {
-		matched, err := provider.Detect(ctx)
+		detection, err := provider.DetectScore(ctx)
 		if err != nil {
 			log.Warnf("Failed to detect provider `%s`: %s", provider.Name(), err.Error())
 			continue
 		}
 
-		if matched {
-			detectedProvider = provider.Name()
+		if !detection.Matched() {
+			continue
+		}
+
+		log.Debugf("Provider `%s` scored %d: %s", provider.Name(), detection.Score, strings.Join(detection.Reasons, ", "))
 
-			// If there are no providers manually specified in the config,
-			if config.Provider == nil {
-				if err := provider.Initialize(ctx); err != nil {
-					ctx.Logger.LogWarn("Failed to initialize provider `%s`: %s", provider.Name(), err.Error())
-					continue
-				}
+		detectedProviders = append(detectedProviders, &DetectedProvider{
+			Name:    provider.Name(),
+			Score:   detection.Score,
+			Reasons: detection.Reasons,
+		})
+		providersByName[provider.Name()] = provider
+	}
+
+	// Highest score first. The sort is stable so the provider order breaks ties
+	slices.SortStableFunc(detectedProviders, func(a, b *DetectedProvider) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
 
-				ctx.Logger.LogInfo("Detected %s", utils.CapitalizeFirst(provider.Name()))
+	var providerToUse providers.Provider
 
-				providerToUse = provider
+	// If there are no providers manually specified in the config, use the best detected provider
+	if len(config.Provider) == 0 {
+		for _, detected := range detectedProviders {
+			provider := providersByName[detected.Name]
+			if err := provider.Initialize(ctx); err != nil {
+				ctx.Logger.LogWarn("Failed to initialize provider `%s`: %s", provider.Name(), err.Error())
+				continue
 			}
 
+			ctx.Logger.LogInfo("Detected %s", utils.CapitalizeFirst(provider.Name()))
+
+			detected.Selected = true
+			providerToUse = provider
 			break
 		}
+
+		return providerToUse, detectedProviders
+	}
+
+	primaryName := config.Provider.Primary()
+	provider := providers.FindProvider(allProviders, primaryName)
+
+	if provider == nil {
+		ctx.Logger.LogWarn("Provider `%s` not found", primaryName)
+		return providerToUse, detectedProviders
+	}
+
+	if err := provider.Initialize(ctx); err != nil {
+		ctx.Logger.LogWarn("Failed to initialize provider `%s`: %s", primaryName, err.Error())
+		return providerToUse, detectedProviders
+	}
+
+	ctx.Logger.LogInfo("Using provider %s from config", utils.CapitalizeFirst(primaryName))
+
+	for _, detected := range detectedProviders {
+		detected.Selected = detected.Name == provider.Name()
 	}
 
-	if config.Provider != nil {
-		provider := providers.GetProvider(*config.Provider)
+	return provider, detectedProviders
+}
+
+// getAuxiliaryProviders finds the providers that build part of the app alongside the primary provider.
+// These are the providers after the first in the config, or the detected providers that can be auxiliary
+func getAuxiliaryProviders(ctx *generate.GenerateContext, config *c.Config, allProviders []providers.Provider, primary providers.Provider, detectedProviders []*DetectedProvider) []providers.AuxiliaryProvider {
+	if primary == nil {
+		return nil
+	}
+
+	composed := []string{}
+	if composing, ok := primary.(providers.ComposingProvider); ok {
+		composed = composing.ComposesWith()
+	}
+
+	names := config.Provider.Auxiliary()
+
+	if len(config.Provider) == 0 {
+		for _, detected := range detectedProviders {
+			if detected.Name == primary.Name() || detected.Score < generate.DetectionScoreManifest || slices.Contains(composed, detected.Name) {
+				continue
+			}
 
+			if _, ok := providers.FindProvider(allProviders, detected.Name).(providers.AuxiliaryProvider); ok {
+				names = append(names, detected.Name)
+			}
+		}
+	}
+
+	auxiliaryProviders := []providers.AuxiliaryProvider{}
+	for _, name := range names {
+		if name == primary.Name() || slices.Contains(composed, name) {
+			ctx.Logger.LogInfo("Provider %s already builds %s", utils.CapitalizeFirst(primary.Name()), utils.CapitalizeFirst(name))
+			continue
+		}
+
+		provider := providers.FindProvider(allProviders, name)
 		if provider == nil {
-			ctx.Logger.LogWarn("Provider `%s` not found", *config.Provider)
-			return providerToUse, detectedProvider
+			ctx.Logger.LogWarn("Provider `%s` not found", name)
+			continue
 		}
 
-		if err := provider.Initialize(ctx); err != nil {
-			ctx.Logger.LogWarn("Failed to initialize provider `%s`: %s", *config.Provider, err.Error())
-			return providerToUse, detectedProvider
+		auxiliary, ok := provider.(providers.AuxiliaryProvider)
+		if !ok {
+			ctx.Logger.LogWarn("Provider `%s` can not be used as an auxiliary provider", name)
+			continue
+		}
+
+		if err := auxiliary.Initialize(ctx); err != nil {
+			ctx.Logger.LogWarn("Failed to initialize provider `%s`: %s", name, err.Error())
+			continue
+		}
+
+		ctx.Logger.LogInfo("Using %s as an auxiliary provider", utils.CapitalizeFirst(name))
+
+		for _, detected := range detectedProviders {
+			if detected.Name == name {
+				detected.Auxiliary = true
+			}
 		}
 
-		ctx.Logger.LogInfo("Using provider %s from config", utils.CapitalizeFirst(*config.Provider))
-		providerToUse = provider
+		auxiliaryProviders = append(auxiliaryProviders, auxiliary)
 	}
 
-	return providerToUse, detectedProvider
+	return auxiliaryProviders
 }
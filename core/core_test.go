@@ -3,10 +3,18 @@ package core
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/gkampitakis/go-snaps/snaps"
 	"github.com/unbindapp/railpack/core/app"
+	"github.com/unbindapp/railpack/core/config"
+	"github.com/unbindapp/railpack/core/generate"
+	"github.com/unbindapp/railpack/core/lockfile"
+	"github.com/unbindapp/railpack/core/logger"
+	"github.com/unbindapp/railpack/core/plan"
+	"github.com/unbindapp/railpack/core/providers"
+	"github.com/unbindapp/railpack/core/resolver"
 	"github.com/stretchr/testify/require"
 )
 
@@ -60,3 +68,281 @@ func TestGenerateBuildPlanForExamples(t *testing.T) {
 		})
 	}
 }
+
+func TestGetProvidersScores(t *testing.T) {
+	pythonWithGo := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(pythonWithGo, "requirements.txt"), []byte("flask\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(pythonWithGo, "main.go"), []byte("package main\n"), 0644))
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+		detected []string
+	}{
+		{
+			name:     "python with main.go",
+			path:     pythonWithGo,
+			expected: "python",
+			detected: []string{"python", "golang"},
+		},
+		{
+			name:     "laravel with package.json",
+			path:     "../examples/php-laravel-12-react",
+			expected: "php",
+			detected: []string{"php", "node", "staticfile"},
+		},
+		{
+			name:     "node",
+			path:     "../examples/node-npm",
+			expected: "node",
+			detected: []string{"node"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userApp, err := app.NewApp(tt.path)
+			require.NoError(t, err)
+
+			ctx := &generate.GenerateContext{
+				App:      userApp,
+				Env:      app.NewEnvironment(nil),
+				Config:   config.EmptyConfig(),
+				Logger:   logger.NewLogger(),
+				Metadata: generate.NewMetadata(),
+			}
+
+			provider, detectedProviders := getProviders(ctx, ctx.Config, providers.GetLanguageProviders())
+			require.NotNil(t, provider)
+			require.Equal(t, tt.expected, provider.Name())
+
+			names := []string{}
+			for _, detected := range detectedProviders {
+				names = append(names, detected.Name)
+				require.NotEmpty(t, detected.Reasons)
+				require.Equal(t, detected.Name == tt.expected, detected.Selected)
+			}
+			require.Equal(t, tt.detected, names)
+		})
+	}
+}
+
+func TestGetProvidersExplicit(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "requirements.txt"), []byte("flask\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pyproject.toml"), []byte("[project]\nname = \"app\"\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.py"), []byte(""), 0644))
+
+	userApp, err := app.NewApp(dir)
+	require.NoError(t, err)
+
+	// An explicitly configured provider wins over a provider with several manifests
+	ctx := &generate.GenerateContext{
+		App:      userApp,
+		Env:      app.NewEnvironment(&map[string]string{"RAILPACK_STATIC_FILE_ROOT": "."}),
+		Config:   config.EmptyConfig(),
+		Logger:   logger.NewLogger(),
+		Metadata: generate.NewMetadata(),
+	}
+
+	provider, detectedProviders := getProviders(ctx, ctx.Config, providers.GetLanguageProviders())
+	require.NotNil(t, provider)
+	require.Equal(t, "staticfile", provider.Name())
+	require.Equal(t, "python", detectedProviders[1].Name)
+	require.Equal(t, generate.DetectionScoreManifest+generate.DetectionScoreEntrypoint, detectedProviders[1].Score)
+}
+
+func TestGetAuxiliaryProviders(t *testing.T) {
+	pythonWithVite := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(pythonWithVite, "requirements.txt"), []byte("flask\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(pythonWithVite, "package.json"), []byte(`{"scripts": {"build": "vite build"}}`), 0644))
+
+	tests := []struct {
+		name      string
+		path      string
+		provider  config.ProviderList
+		primary   string
+		auxiliary []string
+	}{
+		{name: "detected", path: pythonWithVite, primary: "python", auxiliary: []string{"node"}},
+		{name: "config list", path: pythonWithVite, provider: config.ProviderList{"python", "node"}, primary: "python", auxiliary: []string{"node"}},
+		{name: "config single", path: pythonWithVite, provider: config.ProviderList{"python"}, primary: "python", auxiliary: []string{}},
+		{name: "not auxiliary", path: pythonWithVite, provider: config.ProviderList{"node", "python"}, primary: "node", auxiliary: []string{}},
+		{name: "composed by primary", path: "../examples/php-laravel-12-react", primary: "php", auxiliary: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userApp, err := app.NewApp(tt.path)
+			require.NoError(t, err)
+
+			ctx := &generate.GenerateContext{
+				App:      userApp,
+				Env:      app.NewEnvironment(nil),
+				Config:   config.EmptyConfig(),
+				Logger:   logger.NewLogger(),
+				Metadata: generate.NewMetadata(),
+			}
+			ctx.Config.Provider = tt.provider
+
+			primary, detectedProviders := getProviders(ctx, ctx.Config, providers.GetLanguageProviders())
+			require.NotNil(t, primary)
+			require.Equal(t, tt.primary, primary.Name())
+
+			names := []string{}
+			for _, auxiliary := range getAuxiliaryProviders(ctx, ctx.Config, providers.GetLanguageProviders(), primary, detectedProviders) {
+				names = append(names, auxiliary.Name())
+			}
+			require.Equal(t, tt.auxiliary, names)
+		})
+	}
+}
+
+func TestGenerateBuildPlanOffline(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{
+		"engines": {"node": "22"},
+		"scripts": {"start": "node index.js"}
+	}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "package-lock.json"), []byte(`{}`), 0644))
+
+	index := resolver.NewVersionIndex()
+	index.Set("node", []string{"20.9.0", "22.3.0", "22.9.0"})
+	indexData, err := index.Marshal()
+	require.NoError(t, err)
+
+	indexPath := filepath.Join(t.TempDir(), "versions.json")
+	require.NoError(t, os.WriteFile(indexPath, indexData, 0644))
+
+	userApp, err := app.NewApp(dir)
+	require.NoError(t, err)
+
+	options := &GenerateBuildPlanOptions{VersionSources: []string{indexPath}}
+
+	result := GenerateBuildPlan(userApp, app.NewEnvironment(nil), options)
+	require.True(t, result.Success, result.Logs)
+	require.Equal(t, "22.9.0", *result.ResolvedPackages["node"].ResolvedVersion)
+
+	// Lock an older version than the latest in the index
+	lockedVersion := "22.3.0"
+	result.ResolvedPackages["node"].ResolvedVersion = &lockedVersion
+	lock := lockfile.New(result.ResolvedPackages, map[string]string{
+		plan.RAILPACK_BUILDER_IMAGE: "sha256:aaa",
+		plan.RAILPACK_RUNTIME_IMAGE: "sha256:bbb",
+	})
+
+	// A frozen lockfile must exist
+	options.FrozenLockfile = true
+	result = GenerateBuildPlan(userApp, app.NewEnvironment(nil), options)
+	require.False(t, result.Success)
+
+	lockData, err := lock.Marshal()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, lockfile.FileName), lockData, 0644))
+
+	result = GenerateBuildPlan(userApp, app.NewEnvironment(nil), options)
+	require.True(t, result.Success, result.Logs)
+	require.Equal(t, "22.3.0", *result.ResolvedPackages["node"].ResolvedVersion)
+	require.Equal(t, plan.RAILPACK_RUNTIME_IMAGE+"@sha256:bbb", result.Plan.Deploy.Inputs[0].Image)
+
+	// Requesting another version is drift
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{
+		"engines": {"node": "20"},
+		"scripts": {"start": "node index.js"}
+	}`), 0644))
+	result = GenerateBuildPlan(userApp, app.NewEnvironment(nil), options)
+	require.False(t, result.Success)
+
+	options.FrozenLockfile = false
+	result = GenerateBuildPlan(userApp, app.NewEnvironment(nil), options)
+	require.True(t, result.Success, result.Logs)
+	require.Equal(t, "20.9.0", *result.ResolvedPackages["node"].ResolvedVersion)
+}
+
+func TestGenerateBuildPlanBuildArgs(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{
+		"engines": {"node": "22"},
+		"scripts": {"build": "tsc", "start": "node index.js"}
+	}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "package-lock.json"), []byte(`{}`), 0644))
+
+	index := resolver.NewVersionIndex()
+	index.Set("node", []string{"22.9.0"})
+	indexData, err := index.Marshal()
+	require.NoError(t, err)
+
+	indexPath := filepath.Join(t.TempDir(), "versions.json")
+	require.NoError(t, os.WriteFile(indexPath, indexData, 0644))
+
+	userApp, err := app.NewApp(dir)
+	require.NoError(t, err)
+
+	// Build args from the CLI are used by the install and build steps of the provider
+	env := app.NewEnvironment(nil)
+	env.AddBuildArgs([]string{"API_URL=https://example.com"})
+
+	result := GenerateBuildPlan(userApp, env, &GenerateBuildPlanOptions{VersionSources: []string{indexPath}})
+	require.True(t, result.Success, result.Logs)
+
+	for _, name := range []string{"install", "build"} {
+		idx := slices.IndexFunc(result.Plan.Steps, func(step plan.Step) bool { return step.Name == name })
+		require.NotEqual(t, -1, idx, name)
+		require.Equal(t, "https://example.com", result.Plan.Steps[idx].Variables["API_URL"], name)
+	}
+}
+
+func TestGetConfigInterpolation(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "railpack.json"), []byte(`{
+		"secrets": ["API_TOKEN"],
+		"deploy": {
+			"startCommand": "serve --env ${APP_ENV} --token ${API_TOKEN}",
+			"variables": {"APP_ENV": "${APP_ENV:-staging}"}
+		}
+	}`), 0644))
+
+	userApp, err := app.NewApp(dir)
+	require.NoError(t, err)
+
+	// Variables from --env are interpolated unless they are declared as secrets
+	env := app.NewEnvironment(&map[string]string{"APP_ENV": "production", "API_TOKEN": "super-secret"})
+
+	config, err := GetConfig(userApp, env, &GenerateBuildPlanOptions{}, logger.NewLogger())
+	require.NoError(t, err)
+	require.Equal(t, "serve --env production --token ${API_TOKEN}", config.Deploy.StartCmd)
+	require.Equal(t, "production", config.Deploy.Variables["APP_ENV"])
+}
+
+func TestGenerateBuildPlanEOL(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{
+		"engines": {"node": "16"},
+		"scripts": {"start": "node index.js"}
+	}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "package-lock.json"), []byte(`{}`), 0644))
+
+	index := resolver.NewVersionIndex()
+	index.Set("node", []string{"16.20.2", "22.9.0"})
+	indexData, err := index.Marshal()
+	require.NoError(t, err)
+
+	indexPath := filepath.Join(t.TempDir(), "versions.json")
+	require.NoError(t, os.WriteFile(indexPath, indexData, 0644))
+
+	userApp, err := app.NewApp(dir)
+	require.NoError(t, err)
+
+	eolWarning := logger.Msg{Level: logger.Warn, Msg: "node 16 from package.json > engines > node is end-of-life since 2023-09"}
+
+	options := &GenerateBuildPlanOptions{VersionSources: []string{indexPath}}
+	result := GenerateBuildPlan(userApp, app.NewEnvironment(nil), options)
+	require.True(t, result.Success, result.Logs)
+	require.Contains(t, result.Logs, eolWarning)
+
+	options.FailOnEOL = true
+	result = GenerateBuildPlan(userApp, app.NewEnvironment(nil), options)
+	require.False(t, result.Success)
+	require.Contains(t, result.Logs, logger.Msg{Level: logger.Error, Msg: eolWarning.Msg})
+}
@@ -0,0 +1,202 @@
+package eol
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/unbindapp/railpack/core/resolver"
+)
+
+const (
+	dateFormat = "2006-01-02"
+)
+
+//go:embed rules.json
+var defaultRules []byte
+
+// Rules are the end-of-life dates and known vulnerable versions of tools
+type Rules struct {
+	Tools map[string]*ToolRules `json:"tools"`
+}
+
+type ToolRules struct {
+	// Cycles are the release lines of the tool, e.g. `3.7` for Python or `18` for Node
+	Cycles []Cycle `json:"cycles,omitempty"`
+
+	// Vulnerable are the ranges of versions with known vulnerabilities
+	Vulnerable []Vulnerability `json:"vulnerable,omitempty"`
+}
+
+type Cycle struct {
+	Cycle string `json:"cycle"`
+
+	// EOL is the date the cycle stops being supported, as YYYY-MM-DD. An empty date means it is supported
+	EOL string `json:"eol"`
+}
+
+type Vulnerability struct {
+	// Versions is a constraint in the syntax of the tool, e.g. `>=18 <18.19.1`
+	Versions string `json:"versions"`
+	Reason   string `json:"reason"`
+}
+
+// Finding is a resolved package that is end-of-life or has known vulnerabilities
+type Finding struct {
+	Package string
+	Version string
+	Source  string
+	Message string
+}
+
+// Load reads the embedded rules and merges the rules of the override file into them.
+// The override file is skipped when the path is empty
+func Load(overridePath string) (*Rules, error) {
+	rules, err := parseRules(defaultRules, "embedded rules")
+	if err != nil {
+		return nil, err
+	}
+
+	if overridePath == "" {
+		return rules, nil
+	}
+
+	data, err := os.ReadFile(overridePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read EOL rules %s: %w", overridePath, err)
+	}
+
+	override, err := parseRules(data, overridePath)
+	if err != nil {
+		return nil, err
+	}
+
+	rules.Merge(override)
+	return rules, nil
+}
+
+func parseRules(data []byte, name string) (*Rules, error) {
+	rules := &Rules{Tools: map[string]*ToolRules{}}
+	if err := json.Unmarshal(data, rules); err != nil {
+		return nil, fmt.Errorf("error reading EOL rules %s as JSON: %w", name, err)
+	}
+
+	if err := rules.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid EOL rules %s: %w", name, err)
+	}
+
+	return rules, nil
+}
+
+// Validate checks that the dates and version ranges of the rules can be parsed
+func (r *Rules) Validate() error {
+	for tool, toolRules := range r.Tools {
+		for _, cycle := range toolRules.Cycles {
+			if cycle.Cycle == "" {
+				return fmt.Errorf("%s has a cycle without a version", tool)
+			}
+
+			if cycle.EOL != "" {
+				if _, err := time.Parse(dateFormat, cycle.EOL); err != nil {
+					return fmt.Errorf("%s %s has an invalid EOL date %s. Expected YYYY-MM-DD", tool, cycle.Cycle, cycle.EOL)
+				}
+			}
+		}
+
+		for _, vulnerability := range toolRules.Vulnerable {
+			constraint, err := resolver.ParseConstraint(vulnerability.Versions, resolver.ConstraintStyleForPackage(tool))
+			if err != nil {
+				return fmt.Errorf("%s has invalid vulnerable versions: %w", tool, err)
+			}
+
+			// A plain version would match every version of the tool
+			if constraint.IsPlain() {
+				return fmt.Errorf("%s has vulnerable versions `%s` that are not a range, e.g. `>=1.0 <1.2`", tool, vulnerability.Versions)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Merge adds the rules of other. A cycle of other replaces the cycle with the same version, and its
+// vulnerabilities are added to the existing ones
+func (r *Rules) Merge(other *Rules) {
+	for tool, otherRules := range other.Tools {
+		toolRules, ok := r.Tools[tool]
+		if !ok {
+			toolRules = &ToolRules{}
+			r.Tools[tool] = toolRules
+		}
+
+		for _, cycle := range otherRules.Cycles {
+			index := slices.IndexFunc(toolRules.Cycles, func(c Cycle) bool { return c.Cycle == cycle.Cycle })
+			if index >= 0 {
+				toolRules.Cycles[index] = cycle
+			} else {
+				toolRules.Cycles = append(toolRules.Cycles, cycle)
+			}
+		}
+
+		toolRules.Vulnerable = append(toolRules.Vulnerable, otherRules.Vulnerable...)
+	}
+}
+
+// Check finds the resolved packages that are end-of-life at the given time or have known vulnerabilities
+func (r *Rules) Check(packages map[string]*resolver.ResolvedPackage, now time.Time) []Finding {
+	findings := []Finding{}
+
+	for _, name := range slices.Sorted(maps.Keys(packages)) {
+		pkg := packages[name]
+		toolRules, ok := r.Tools[name]
+		if !ok || pkg.ResolvedVersion == nil {
+			continue
+		}
+
+		version := *pkg.ResolvedVersion
+		finding := Finding{Package: name, Version: version, Source: pkg.Source}
+
+		if cycle, ok := toolRules.cycle(version); ok && cycle.EOL != "" {
+			eol, _ := time.Parse(dateFormat, cycle.EOL)
+			if !now.Before(eol) {
+				finding.Message = fmt.Sprintf("%s %s from %s is end-of-life since %s", name, cycle.Cycle, pkg.Source, eol.Format("2006-01"))
+				findings = append(findings, finding)
+			}
+		}
+
+		for _, vulnerability := range toolRules.Vulnerable {
+			constraint, err := resolver.ParseConstraint(vulnerability.Versions, resolver.ConstraintStyleForPackage(name))
+			if err != nil || !constraint.Check(version) {
+				continue
+			}
+
+			finding.Message = fmt.Sprintf("%s %s from %s has known vulnerabilities: %s", name, version, pkg.Source, vulnerability.Reason)
+			findings = append(findings, finding)
+		}
+	}
+
+	return findings
+}
+
+// cycle finds the most specific cycle that the version belongs to
+func (t *ToolRules) cycle(version string) (Cycle, bool) {
+	var match Cycle
+	found := false
+
+	for _, cycle := range t.Cycles {
+		if version != cycle.Cycle && !strings.HasPrefix(version, cycle.Cycle+".") {
+			continue
+		}
+
+		if !found || len(cycle.Cycle) > len(match.Cycle) {
+			match, found = cycle, true
+		}
+	}
+
+	return match, found
+}
@@ -0,0 +1,87 @@
+package eol
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/unbindapp/railpack/core/resolver"
+)
+
+func resolved(name, version, source string) *resolver.ResolvedPackage {
+	return &resolver.ResolvedPackage{Name: name, ResolvedVersion: &version, Source: source}
+}
+
+func TestCheck(t *testing.T) {
+	rules, err := Load("")
+	require.NoError(t, err)
+
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	findings := rules.Check(map[string]*resolver.ResolvedPackage{
+		"python": resolved("python", "3.7.17", ".python-version"),
+		"node":   resolved("node", "20.11.0", "package.json > engines > node"),
+		"php":    resolved("php", "8.3.8", "composer.json > require > php"),
+		"bun":    resolved("bun", "1.1.0", resolver.DefaultSource),
+	}, now)
+
+	require.Equal(t, []Finding{
+		{
+			Package: "node",
+			Version: "20.11.0",
+			Source:  "package.json > engines > node",
+			Message: "node 20.11.0 from package.json > engines > node has known vulnerabilities: CVE-2024-21892 and other issues fixed in the February 2024 security releases",
+		},
+		{
+			Package: "python",
+			Version: "3.7.17",
+			Source:  ".python-version",
+			Message: "python 3.7 from .python-version is end-of-life since 2023-06",
+		},
+	}, findings)
+
+	// Cycles only match whole version parts
+	findings = rules.Check(map[string]*resolver.ResolvedPackage{
+		"python": resolved("python", "3.10.14", resolver.DefaultSource),
+	}, now)
+	require.Empty(t, findings)
+}
+
+func TestLoadOverride(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "eol.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"tools": {
+			"python": {"cycles": [{"cycle": "3.7", "eol": ""}, {"cycle": "3.12", "eol": "2024-01-01"}]},
+			"bun": {"vulnerable": [{"versions": "<1.1.5", "reason": "internal advisory"}]}
+		}
+	}`), 0644))
+
+	rules, err := Load(path)
+	require.NoError(t, err)
+
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	findings := rules.Check(map[string]*resolver.ResolvedPackage{
+		"python": resolved("python", "3.7.17", ".python-version"),
+		"bun":    resolved("bun", "1.1.0", resolver.DefaultSource),
+	}, now)
+	require.Len(t, findings, 1)
+	require.Equal(t, "bun", findings[0].Package)
+
+	findings = rules.Check(map[string]*resolver.ResolvedPackage{
+		"python": resolved("python", "3.12.4", ".python-version"),
+	}, now)
+	require.Len(t, findings, 1)
+
+	invalid := []string{
+		`{"tools": {"node": {"cycles": [{"cycle": "18", "eol": "April 2025"}]}}}`,
+		`{"tools": {"node": {"vulnerable": [{"versions": "18.19.0"}]}}}`,
+		`{"tools": {"node": {"vulnerable": [{"versions": ">=lts"}]}}}`,
+		`{"tools": `,
+	}
+	for _, contents := range invalid {
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0644))
+		_, err := Load(path)
+		require.Error(t, err, contents)
+	}
+}
@@ -2,6 +2,7 @@ package generate
 
 import (
 	"maps"
+	"slices"
 
 	"github.com/charmbracelet/log"
 	a "github.com/unbindapp/railpack/core/app"
@@ -16,6 +17,8 @@ type CommandStepBuilder struct {
 	Variables   map[string]string
 	Caches      []string
 	Secrets     []string
+	SecretFiles []plan.SecretFile
+	BuildArgs   []string
 	app         *a.App
 	env         *a.Environment
 }
@@ -29,6 +32,7 @@ func (c *GenerateContext) NewCommandStep(name string) *CommandStepBuilder {
 		Variables:   map[string]string{},
 		Caches:      []string{},
 		Secrets:     []string{"*"},
+		BuildArgs:   []string{},
 		app:         c.App,
 		env:         c.Env,
 	}
@@ -107,6 +111,25 @@ func (b *CommandStepBuilder) UseSecrets(secrets []string) {
 	}
 }
 
+// UseSecretFile mounts the secret as a file at the target path instead of an environment variable
+func (b *CommandStepBuilder) UseSecretFile(secret, target string) {
+	b.SecretFiles = append(b.SecretFiles, plan.SecretFile{Secret: secret, Target: target})
+}
+
+// UseBuildArgs adds the build args to this step. Steps do not use any build args unless they name them
+func (b *CommandStepBuilder) UseBuildArgs(names ...string) {
+	for _, name := range names {
+		if !slices.Contains(b.BuildArgs, name) {
+			b.BuildArgs = append(b.BuildArgs, name)
+		}
+	}
+}
+
+// UsesBuildArg checks if the build arg is added to this step. A "*" adds all build args
+func (b *CommandStepBuilder) UsesBuildArg(name string) bool {
+	return slices.Contains(b.BuildArgs, "*") || slices.Contains(b.BuildArgs, name)
+}
+
 func (b *CommandStepBuilder) Name() string {
 	return b.DisplayName
 }
@@ -119,7 +142,19 @@ func (b *CommandStepBuilder) Build(options *BuildStepOptions) (*plan.Step, error
 	step.Assets = b.Assets
 	step.Caches = b.Caches
 	step.Variables = b.Variables
+
+	// Build args are added as variables of the steps that use them. Variables set on the step take precedence
+	if len(options.BuildArgs) > 0 {
+		step.Variables = maps.Clone(b.Variables)
+		for name, value := range options.BuildArgs {
+			if _, ok := step.Variables[name]; ok || !b.UsesBuildArg(name) {
+				continue
+			}
+			step.Variables[name] = value
+		}
+	}
 	step.Secrets = b.Secrets
+	step.SecretFiles = b.SecretFiles
 
 	return step, nil
 }
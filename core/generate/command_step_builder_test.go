@@ -0,0 +1,45 @@
+package generate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandStepBuildArgs(t *testing.T) {
+	buildArgs := map[string]string{
+		"NODE_ENV":  "production",
+		"LOG_LEVEL": "debug",
+	}
+
+	builder := &CommandStepBuilder{
+		DisplayName: "build",
+		Variables:   map[string]string{"NODE_ENV": "test"},
+		BuildArgs:   []string{"*"},
+	}
+
+	step, err := builder.Build(&BuildStepOptions{BuildArgs: buildArgs})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"NODE_ENV": "test", "LOG_LEVEL": "debug"}, step.Variables)
+
+	// The variables of the builder are not modified
+	require.Equal(t, map[string]string{"NODE_ENV": "test"}, builder.Variables)
+
+	builder.BuildArgs = []string{"LOG_LEVEL"}
+	builder.Variables = map[string]string{}
+	step, err = builder.Build(&BuildStepOptions{BuildArgs: buildArgs})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"LOG_LEVEL": "debug"}, step.Variables)
+
+	// Build args are not used unless the step names them
+	builder = &CommandStepBuilder{DisplayName: "build", Variables: map[string]string{}, BuildArgs: []string{}}
+	step, err = builder.Build(&BuildStepOptions{BuildArgs: buildArgs})
+	require.NoError(t, err)
+	require.Empty(t, step.Variables)
+
+	builder.UseBuildArgs("NODE_ENV", "NODE_ENV")
+	require.Equal(t, []string{"NODE_ENV"}, builder.BuildArgs)
+	step, err = builder.Build(&BuildStepOptions{BuildArgs: buildArgs})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"NODE_ENV": "production"}, step.Variables)
+}
@@ -20,6 +20,7 @@ import (
 type BuildStepOptions struct {
 	ResolvedPackages map[string]*resolver.ResolvedPackage
 	Caches           *CacheContext
+	BuildArgs        map[string]string
 }
 
 type StepBuilder interface {
@@ -41,10 +42,19 @@ type GenerateContext struct {
 
 	SubContexts []string
 
+	// The name of the provider used to plan the build
+	ProviderName string
+
+	// The names of the providers that build part of the app alongside the primary provider
+	AuxiliaryProviderNames []string
+
 	Metadata        *Metadata
 	Resolver        *resolver.Resolver
 	MiseStepBuilder *MiseStepBuilder
 
+	// The step that installs only the runtime packages of the mise step, if there are build-only packages
+	MiseRuntimeStepBuilder *MiseStepBuilder
+
 	Logger *logger.Logger
 }
 
@@ -64,10 +74,13 @@ func (c CommandWrapper) IsSpread() bool {
 }
 
 func NewGenerateContext(app *a.App, env *a.Environment, config *config.Config, logger *logger.Logger) (*GenerateContext, error) {
+	hints := resolver.FindVersionHints(app, env)
+
 	resolver, err := resolver.NewResolver(mise.InstallDir)
 	if err != nil {
 		return nil, err
 	}
+	resolver.SetVersionHints(hints)
 
 	ctx := &GenerateContext{
 		App:      app,
@@ -128,7 +141,9 @@ func (c *GenerateContext) ResolvePackages() (map[string]*resolver.ResolvedPackag
 
 // Generate a build plan from the context
 func (c *GenerateContext) Generate() (*plan.BuildPlan, map[string]*resolver.ResolvedPackage, error) {
-	c.applyConfig()
+	if err := c.applyConfig(); err != nil {
+		return nil, nil, err
+	}
 
 	// Resolve all package versions into a fully qualified and valid version
 	resolvedPackages, err := c.ResolvePackages()
@@ -142,6 +157,7 @@ func (c *GenerateContext) Generate() (*plan.BuildPlan, map[string]*resolver.Reso
 	buildStepOptions := &BuildStepOptions{
 		ResolvedPackages: resolvedPackages,
 		Caches:           c.Caches,
+		BuildArgs:        c.Config.BuildArgs,
 	}
 
 	for _, stepBuilder := range c.Steps {
@@ -154,6 +170,8 @@ func (c *GenerateContext) Generate() (*plan.BuildPlan, map[string]*resolver.Reso
 		buildPlan.AddStep(*step)
 	}
 
+	c.warnUnusedBuildArgs()
+
 	buildPlan.Caches = c.Caches.Caches
 	buildPlan.Secrets = utils.RemoveDuplicates(c.Secrets)
 	buildPlan.Deploy = c.Deploy.Build()
@@ -161,6 +179,19 @@ func (c *GenerateContext) Generate() (*plan.BuildPlan, map[string]*resolver.Reso
 	return buildPlan, resolvedPackages, nil
 }
 
+// warnUnusedBuildArgs warns about build args that no step uses, since they have no effect on the build
+func (c *GenerateContext) warnUnusedBuildArgs() {
+	for _, name := range slices.Sorted(maps.Keys(c.Config.BuildArgs)) {
+		used := slices.ContainsFunc(c.Steps, func(step StepBuilder) bool {
+			commandStep, ok := step.(*CommandStepBuilder)
+			return ok && commandStep.UsesBuildArg(name)
+		})
+		if !used {
+			log.Warnf("Build arg `%s` is not used by any step. Add it to the `buildArgs` of a step to use it", name)
+		}
+	}
+}
+
 func (c *GenerateContext) DefaultRuntimeInput() plan.Input {
 	return c.DefaultRuntimeInputWithPackages([]string{})
 }
@@ -179,6 +210,27 @@ func (c *GenerateContext) DefaultRuntimeInputWithPackages(additionalAptPackages
 	return plan.NewStepInput(runtimeAptStep.Name())
 }
 
+// MiseRuntimeInput is the deploy input with the mise packages that are needed to run the app.
+// When there are build-only packages, a separate step installs only the runtime packages
+// so that the build-only packages are not shipped in the runtime image
+func (c *GenerateContext) MiseRuntimeInput() plan.Input {
+	miseStep := c.GetMiseStepBuilder()
+	if len(miseStep.BuildOnlyPackages) == 0 && c.MiseRuntimeStepBuilder == nil {
+		return plan.NewStepInput(miseStep.Name(), plan.InputOptions{
+			Include: miseStep.GetOutputPaths(),
+		})
+	}
+
+	if c.MiseRuntimeStepBuilder == nil {
+		c.MiseRuntimeStepBuilder = c.NewMiseStepBuilder(MiseRuntimePackageStepName)
+		c.MiseRuntimeStepBuilder.runtimeOf = miseStep
+	}
+
+	return plan.NewStepInput(c.MiseRuntimeStepBuilder.Name(), plan.InputOptions{
+		Include: c.MiseRuntimeStepBuilder.GetOutputPaths(),
+	})
+}
+
 func (o *BuildStepOptions) NewAptInstallCommand(pkgs []string) plan.Command {
 	pkgs = utils.RemoveDuplicates(pkgs)
 	sort.Strings(pkgs)
@@ -188,14 +240,37 @@ func (o *BuildStepOptions) NewAptInstallCommand(pkgs []string) plan.Command {
 	})
 }
 
-func (c *GenerateContext) applyConfig() {
-	miseStep := c.GetMiseStepBuilder()
-	for _, pkg := range slices.Sorted(maps.Keys(c.Config.Packages)) {
-		version := c.Config.Packages[pkg]
-		pkgRef := miseStep.Default(pkg, version)
-		miseStep.Version(pkgRef, version, "custom config")
+// MatchesCondition checks if all the conditions that are set match the current build
+func (c *GenerateContext) MatchesCondition(when *config.When) bool {
+	if when == nil {
+		return true
 	}
 
+	for name, value := range when.Env {
+		if c.Env.GetVariable(name) != value {
+			return false
+		}
+	}
+
+	if when.Provider != "" && when.Provider != c.ProviderName && !slices.Contains(c.AuxiliaryProviderNames, when.Provider) {
+		return false
+	}
+
+	if when.File != "" && !c.App.HasMatch(when.File) {
+		return false
+	}
+
+	return true
+}
+
+func (c *GenerateContext) applyConfig() error {
+	c.Config = c.Config.ApplyOverrides(c.MatchesCondition)
+
+	if err := c.applyConfigPackages(slices.Sorted(maps.Keys(c.Config.Packages))); err != nil {
+		return err
+	}
+	miseStep := c.GetMiseStepBuilder()
+
 	// Apply the cache config to the context
 	maps.Copy(c.Caches.Caches, c.Config.Caches)
 	c.Secrets = plan.SpreadStrings(c.Config.Secrets, c.Secrets)
@@ -203,6 +278,10 @@ func (c *GenerateContext) applyConfig() {
 	// Apply step config to the context
 	for _, name := range slices.Sorted(maps.Keys(c.Config.Steps)) {
 		configStep := c.Config.Steps[name]
+		if !c.MatchesCondition(configStep.When) {
+			log.Debugf("Skipping step `%s` since its conditions do not match", name)
+			continue
+		}
 
 		var commandStepBuilder *CommandStepBuilder
 
@@ -227,10 +306,21 @@ func (c *GenerateContext) applyConfig() {
 		commandStepBuilder.Inputs = plan.Spread(configStep.Inputs, commandStepBuilder.Inputs)
 
 		commandStepBuilder.Secrets = plan.SpreadStrings(configStep.Secrets, commandStepBuilder.Secrets)
+		commandStepBuilder.SecretFiles = append(commandStepBuilder.SecretFiles, configStep.SecretFiles...)
+		commandStepBuilder.BuildArgs = plan.SpreadStrings(configStep.BuildArgs, commandStepBuilder.BuildArgs)
 
 		commandStepBuilder.Caches = plan.SpreadStrings(configStep.Caches, commandStepBuilder.Caches)
 		commandStepBuilder.AddEnvVars(configStep.Variables)
 		maps.Copy(commandStepBuilder.Assets, configStep.Assets)
+
+		for _, assetName := range slices.Sorted(maps.Keys(configStep.AssetFiles)) {
+			assetFile := configStep.AssetFiles[assetName]
+			contents, err := c.templateAssetFile(assetFile.File)
+			if err != nil {
+				return fmt.Errorf("failed to load asset `%s` of step `%s` from %s: %w", assetName, name, assetFile.File, err)
+			}
+			commandStepBuilder.Assets[assetName] = contents
+		}
 	}
 
 	// Update deploy from config
@@ -239,9 +329,14 @@ func (c *GenerateContext) applyConfig() {
 			c.Deploy.StartCmd = c.Config.Deploy.StartCmd
 		}
 
+		if c.Config.Deploy.ReleaseCmd != "" {
+			c.Deploy.ReleaseCmd = c.Config.Deploy.ReleaseCmd
+		}
+
 		c.Deploy.Inputs = plan.Spread(c.Config.Deploy.Inputs, c.Deploy.Inputs)
 		c.Deploy.Paths = plan.SpreadStrings(c.Config.Deploy.Paths, c.Deploy.Paths)
 		maps.Copy(c.Deploy.Variables, c.Config.Deploy.Variables)
 	}
 
+	return nil
 }
@@ -2,6 +2,8 @@ package generate
 
 import (
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/gkampitakis/go-snaps/snaps"
@@ -104,3 +106,37 @@ func TestGenerateContext(t *testing.T) {
 
 	snaps.MatchJSON(t, serializedPlan)
 }
+
+func TestTemplateAssetFile(t *testing.T) {
+	appDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(appDir, "deploy"), 0755))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(appDir, "deploy", "nginx.conf"),
+		[]byte("listen {{ .Env.PORT }};\nworkers {{ .Env.WORKERS }};\nprovider {{ .Provider }} {{ .Metadata.nodeRuntime }};\ntoken '{{ .Env.API_TOKEN }}';"),
+		0644,
+	))
+
+	userApp, err := app.NewApp(appDir)
+	require.NoError(t, err)
+
+	ctx := &GenerateContext{
+		App: userApp,
+		Env: app.NewEnvironment(&map[string]string{
+			"PORT":      "8080",
+			"API_TOKEN": "super-secret",
+		}),
+		Config:       config.EmptyConfig(),
+		Secrets:      []string{"API_TOKEN"},
+		Metadata:     NewMetadata(),
+		ProviderName: "node",
+	}
+	ctx.Config.BuildArgs = map[string]string{"WORKERS": "4"}
+	ctx.Metadata.Set("nodeRuntime", "vite")
+
+	contents, err := ctx.templateAssetFile("deploy/nginx.conf")
+	require.NoError(t, err)
+	require.Equal(t, "listen 8080;\nworkers 4;\nprovider node vite;\ntoken '';", contents)
+
+	_, err = ctx.templateAssetFile("deploy/missing.conf")
+	require.Error(t, err)
+}
@@ -5,6 +5,7 @@ import "github.com/unbindapp/railpack/core/plan"
 type DeployBuilder struct {
 	Inputs      []plan.Input
 	StartCmd    string
+	ReleaseCmd  string
 	Variables   map[string]string
 	Paths       []string
 	AptPackages []string
@@ -14,6 +15,7 @@ func NewDeployBuilder() *DeployBuilder {
 	return &DeployBuilder{
 		Inputs:      []plan.Input{},
 		StartCmd:    "",
+		ReleaseCmd:  "",
 		Variables:   map[string]string{},
 		Paths:       []string{},
 		AptPackages: []string{},
@@ -22,9 +24,10 @@ func NewDeployBuilder() *DeployBuilder {
 
 func (b *DeployBuilder) Build() plan.Deploy {
 	return plan.Deploy{
-		Inputs:    b.Inputs,
-		StartCmd:  b.StartCmd,
-		Variables: b.Variables,
-		Paths:     b.Paths,
+		Inputs:     b.Inputs,
+		StartCmd:   b.StartCmd,
+		ReleaseCmd: b.ReleaseCmd,
+		Variables:  b.Variables,
+		Paths:      b.Paths,
 	}
 }
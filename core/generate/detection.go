@@ -0,0 +1,56 @@
+package generate
+
+import (
+	"fmt"
+	"slices"
+)
+
+const (
+	// DetectionScoreExplicit is used when the app is explicitly configured for a provider. It is higher than all
+	// other kinds of evidence together, so explicit config always wins
+	DetectionScoreExplicit = 1000
+
+	// DetectionScoreManifest is used for a manifest or lock file of the language (e.g. go.mod)
+	DetectionScoreManifest = 100
+
+	// DetectionScoreEntrypoint is used for a source file that is commonly an entrypoint (e.g. main.py)
+	DetectionScoreEntrypoint = 30
+
+	// DetectionScoreFallback is used for evidence that is common in apps of other languages
+	DetectionScoreFallback = 10
+)
+
+// Detection is the evidence a provider found that it can build the app.
+// The score is the sum of the scores of each kind of evidence, and the provider with the highest score is used
+type Detection struct {
+	Score   int      `json:"score"`
+	Reasons []string `json:"reasons,omitempty"`
+
+	// scored are the kinds of evidence that are part of the score
+	scored []int
+}
+
+// Add records a piece of evidence with its score. Each kind of evidence is only scored once, so an app with several
+// manifests of a language (e.g. requirements.txt and pyproject.toml) does not outweigh other providers
+func (d *Detection) Add(score int, reason string) {
+	if !slices.Contains(d.scored, score) {
+		d.Score += score
+		d.scored = append(d.scored, score)
+	}
+	d.Reasons = append(d.Reasons, reason)
+}
+
+// AddMatch records the evidence if the app has a file or directory matching the pattern
+func (d *Detection) AddMatch(ctx *GenerateContext, pattern string, score int) bool {
+	if !ctx.App.HasMatch(pattern) {
+		return false
+	}
+
+	d.Add(score, fmt.Sprintf("found %s", pattern))
+	return true
+}
+
+// Matched checks if there is any evidence for the provider
+func (d *Detection) Matched() bool {
+	return d != nil && d.Score > 0
+}
@@ -0,0 +1,23 @@
+package generate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectionScoresEachKindOnce(t *testing.T) {
+	detection := &Detection{}
+	detection.Add(DetectionScoreManifest, "found requirements.txt")
+	detection.Add(DetectionScoreManifest, "found pyproject.toml")
+	detection.Add(DetectionScoreEntrypoint, "found main.py")
+
+	require.Equal(t, DetectionScoreManifest+DetectionScoreEntrypoint, detection.Score)
+	require.Equal(t, []string{"found requirements.txt", "found pyproject.toml", "found main.py"}, detection.Reasons)
+
+	// Explicit config wins over every other kind of evidence together
+	detection.Add(DetectionScoreFallback, "found public")
+	explicit := &Detection{}
+	explicit.Add(DetectionScoreExplicit, "RAILPACK_STATIC_FILE_ROOT is set")
+	require.Greater(t, explicit.Score, detection.Score)
+}
@@ -0,0 +1,110 @@
+package generate
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/unbindapp/railpack/core/plan"
+)
+
+// DownloadStepBuilder downloads a file, checks its checksum and installs it as a binary.
+// Archives are extracted, so a binary can be at the root of the archive or in its `bin` directory
+type DownloadStepBuilder struct {
+	DisplayName string
+	BinName     string
+	URL         string
+	Checksum    string
+}
+
+func (c *GenerateContext) NewDownloadStepBuilder(binName, url, checksum string) *DownloadStepBuilder {
+	step := &DownloadStepBuilder{
+		DisplayName: c.GetStepName("packages:url:" + binName),
+		BinName:     binName,
+		URL:         url,
+		Checksum:    checksum,
+	}
+
+	c.Steps = append(c.Steps, step)
+
+	return step
+}
+
+func (b *DownloadStepBuilder) Name() string {
+	return b.DisplayName
+}
+
+func (b *DownloadStepBuilder) GetOutputPaths() []string {
+	return []string{b.getBinPath()}
+}
+
+func (b *DownloadStepBuilder) Build(options *BuildStepOptions) (*plan.Step, error) {
+	step := plan.NewStep(b.DisplayName)
+
+	step.Inputs = []plan.Input{
+		plan.NewImageInput(plan.RAILPACK_BUILDER_IMAGE),
+	}
+
+	fileURL, err := url.Parse(b.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL of %s: %w", b.BinName, err)
+	}
+
+	// The file is saved under a fixed name, so nothing in the URL ends up in its path
+	binPath := b.getBinPath()
+	extension := archiveExtension(fileURL.Path)
+	file := fmt.Sprintf("/tmp/railpack-download-%s%s", b.BinName, extension)
+
+	// Every value is quoted, since URLs can have characters like `&` and `;`
+	download := fmt.Sprintf("curl -fsSL -o %s %s && echo %s | sha256sum -c - && mkdir -p %s && %s",
+		shellQuote(file), shellQuote(b.URL), shellQuote(b.Checksum+"  "+file), shellQuote(binPath),
+		b.installCommand(extension, file, binPath))
+
+	step.AddCommands([]plan.Command{
+		plan.NewExecCommand("sh -c "+shellQuote(download), plan.ExecOptions{CustomName: fmt.Sprintf("download %s", b.BinName)}),
+		plan.NewPathCommand(binPath),
+		plan.NewPathCommand(fmt.Sprintf("%s/bin", binPath)),
+	})
+
+	step.Secrets = []string{}
+
+	return step, nil
+}
+
+// archiveExtension returns the archive extension of the file name of a URL path, or an empty string if the file
+// is not an archive
+func archiveExtension(urlPath string) string {
+	name := path.Base(urlPath)
+	for _, extension := range []string{".tar.gz", ".tgz", ".tar.xz", ".tar", ".zip"} {
+		if strings.HasSuffix(name, extension) {
+			return extension
+		}
+	}
+	return ""
+}
+
+// installCommand extracts an archive, or installs the file as the binary
+func (b *DownloadStepBuilder) installCommand(extension, file, binPath string) string {
+	switch extension {
+	case ".tar.gz", ".tgz":
+		return fmt.Sprintf("tar -xzf %s -C %s", shellQuote(file), shellQuote(binPath))
+	case ".tar.xz":
+		return fmt.Sprintf("tar -xJf %s -C %s", shellQuote(file), shellQuote(binPath))
+	case ".tar":
+		return fmt.Sprintf("tar -xf %s -C %s", shellQuote(file), shellQuote(binPath))
+	case ".zip":
+		return fmt.Sprintf("unzip -q %s -d %s", shellQuote(file), shellQuote(binPath))
+	default:
+		return fmt.Sprintf("install -m 755 %s %s", shellQuote(file), shellQuote(binPath+"/"+b.BinName))
+	}
+}
+
+// shellQuote quotes a value for a POSIX shell
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+func (b *DownloadStepBuilder) getBinPath() string {
+	return fmt.Sprintf("%s/%s", BinDir, b.BinName)
+}
@@ -2,6 +2,7 @@ package generate
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/unbindapp/railpack/core/plan"
 	"github.com/unbindapp/railpack/core/resolver"
@@ -72,6 +73,11 @@ func (b *InstallBinStepBuilder) Build(options *BuildStepOptions) (*plan.Step, er
 	return step, nil
 }
 
+// getBinPath is named after the package, without the mise backend and the owner, e.g. `ubi:cli/cli` is installed to `cli`
 func (b *InstallBinStepBuilder) getBinPath() string {
-	return fmt.Sprintf("%s/%s", BinDir, b.Package.Name)
+	name := b.Package.Name
+	if i := strings.LastIndexAny(name, ":/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return fmt.Sprintf("%s/%s", BinDir, name)
 }
@@ -13,7 +13,8 @@ import (
 )
 
 const (
-	MisePackageStepName = "packages:mise"
+	MisePackageStepName        = "packages:mise"
+	MiseRuntimePackageStepName = "packages:mise:runtime"
 )
 
 type MiseStepBuilder struct {
@@ -25,8 +26,15 @@ type MiseStepBuilder struct {
 	Assets                map[string]string
 	Inputs                []plan.Input
 	Variables             map[string]string
-	app                   *a.App
-	env                   *a.Environment
+
+	// BuildOnlyPackages are the names of the packages that are only needed to build the app
+	BuildOnlyPackages map[string]bool
+
+	// runtimeOf is the step whose runtime packages this step installs
+	runtimeOf *MiseStepBuilder
+
+	app *a.App
+	env *a.Environment
 }
 
 func (c *GenerateContext) NewMiseStepBuilder(displayName string) *MiseStepBuilder {
@@ -40,6 +48,7 @@ func (c *GenerateContext) NewMiseStepBuilder(displayName string) *MiseStepBuilde
 		Assets:                map[string]string{},
 		Inputs:                []plan.Input{},
 		Variables:             map[string]string{},
+		BuildOnlyPackages:     map[string]bool{},
 		app:                   c.App,
 		env:                   c.Env,
 	}
@@ -63,7 +72,23 @@ func (b *MiseStepBuilder) AddInput(input plan.Input) {
 	b.Inputs = append(b.Inputs, input)
 }
 
+// Default adds a package that is needed to run the app
 func (b *MiseStepBuilder) Default(name string, defaultVersion string) resolver.PackageRef {
+	// A package that is needed at runtime by anyone is shipped, even if it was added as build-only
+	delete(b.BuildOnlyPackages, name)
+	return b.addPackage(name, defaultVersion)
+}
+
+// DefaultBuildOnly adds a package that is only needed to build the app, e.g. a package manager.
+// It is left out of the runtime image when the deploy uses MiseRuntimeInput
+func (b *MiseStepBuilder) DefaultBuildOnly(name string, defaultVersion string) resolver.PackageRef {
+	if !b.hasPackage(name) {
+		b.BuildOnlyPackages[name] = true
+	}
+	return b.addPackage(name, defaultVersion)
+}
+
+func (b *MiseStepBuilder) addPackage(name string, defaultVersion string) resolver.PackageRef {
 	for _, pkg := range b.MisePackages {
 		if pkg.Name == name {
 			return *pkg
@@ -75,6 +100,35 @@ func (b *MiseStepBuilder) Default(name string, defaultVersion string) resolver.P
 	return pkg
 }
 
+func (b *MiseStepBuilder) hasPackage(name string) bool {
+	for _, pkg := range b.MisePackages {
+		if pkg.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// RuntimePackages are the packages that are not build-only
+func (b *MiseStepBuilder) RuntimePackages() []*resolver.PackageRef {
+	packages := []*resolver.PackageRef{}
+	for _, pkg := range b.MisePackages {
+		if !b.BuildOnlyPackages[pkg.Name] {
+			packages = append(packages, pkg)
+		}
+	}
+	return packages
+}
+
+// packagesToInstall are the packages the step installs. A runtime step installs the runtime
+// packages of its build step, which are only known once all providers have added their packages
+func (b *MiseStepBuilder) packagesToInstall() []*resolver.PackageRef {
+	if b.runtimeOf != nil {
+		return b.runtimeOf.RuntimePackages()
+	}
+	return b.MisePackages
+}
+
 func (b *MiseStepBuilder) Version(name resolver.PackageRef, version string, source string) {
 	b.Resolver.Version(name, version, source)
 }
@@ -93,9 +147,10 @@ func (b *MiseStepBuilder) GetOutputPaths() []string {
 func (b *MiseStepBuilder) Build(options *BuildStepOptions) (*plan.Step, error) {
 	step := plan.NewStep(b.DisplayName)
 
-	step.Inputs = []plan.Input{
+	// Other inputs are copied on top of the builder image, e.g. binaries of the config packages
+	step.Inputs = append([]plan.Input{
 		plan.NewImageInput(plan.RAILPACK_BUILDER_IMAGE),
-	}
+	}, b.Inputs...)
 
 	// Setup apt commands
 	// TODO: This should be a separate step
@@ -106,7 +161,8 @@ func (b *MiseStepBuilder) Build(options *BuildStepOptions) (*plan.Step, error) {
 		step.Caches = options.Caches.GetAptCaches()
 	}
 
-	if len(b.MisePackages) == 0 {
+	misePackages := b.packagesToInstall()
+	if len(misePackages) == 0 {
 		return step, nil
 	}
 
@@ -121,6 +177,9 @@ func (b *MiseStepBuilder) Build(options *BuildStepOptions) (*plan.Step, error) {
 		"MISE_SHIMS_DIR":    "/mise/shims",
 		"MISE_INSTALLS_DIR": "/mise/installs",
 	})
+	if b.runtimeOf != nil {
+		maps.Copy(step.Variables, b.runtimeOf.Variables)
+	}
 	maps.Copy(step.Variables, b.Variables)
 
 	if verbose := b.env.GetVariable("MISE_VERBOSE"); verbose != "" {
@@ -137,7 +196,7 @@ func (b *MiseStepBuilder) Build(options *BuildStepOptions) (*plan.Step, error) {
 
 	// Setup mise commands
 	packagesToInstall := make(map[string]string)
-	for _, pkg := range b.MisePackages {
+	for _, pkg := range misePackages {
 		resolved, ok := options.ResolvedPackages[pkg.Name]
 		if ok && resolved.ResolvedVersion != nil {
 			packagesToInstall[pkg.Name] = *resolved.ResolvedVersion
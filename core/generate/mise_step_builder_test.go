@@ -0,0 +1,84 @@
+package generate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/unbindapp/railpack/core/plan"
+	"github.com/unbindapp/railpack/core/resolver"
+)
+
+func TestMiseBuildOnlyPackages(t *testing.T) {
+	ctx := CreateTestContext(t, "../../examples/node-npm")
+	miseStep := ctx.GetMiseStepBuilder()
+
+	miseStep.Default("python", "3.13")
+	miseStep.DefaultBuildOnly("pipx", "latest")
+	miseStep.DefaultBuildOnly("maven", "latest")
+
+	// A package that is needed at runtime by anyone is shipped
+	miseStep.Default("maven", "latest")
+	miseStep.DefaultBuildOnly("python", "3.13")
+
+	require.Equal(t, map[string]bool{"pipx": true}, miseStep.BuildOnlyPackages)
+
+	names := []string{}
+	for _, pkg := range miseStep.RuntimePackages() {
+		names = append(names, pkg.Name)
+	}
+	require.Equal(t, []string{"python", "maven"}, names)
+}
+
+func TestMiseRuntimeInput(t *testing.T) {
+	t.Run("without build-only packages", func(t *testing.T) {
+		ctx := CreateTestContext(t, "../../examples/node-npm")
+		ctx.GetMiseStepBuilder().Default("node", "22")
+
+		input := ctx.MiseRuntimeInput()
+		require.Equal(t, MisePackageStepName, input.Step)
+		require.Nil(t, ctx.MiseRuntimeStepBuilder)
+	})
+
+	t.Run("with build-only packages", func(t *testing.T) {
+		ctx := CreateTestContext(t, "../../examples/node-npm")
+		miseStep := ctx.GetMiseStepBuilder()
+		miseStep.Default("node", "22")
+		miseStep.DefaultBuildOnly("pnpm", "latest")
+		miseStep.Variables["MISE_NODE_COREPACK"] = "true"
+
+		input := ctx.MiseRuntimeInput()
+		require.Equal(t, MiseRuntimePackageStepName, input.Step)
+		require.Equal(t, ctx.MiseRuntimeStepBuilder.GetOutputPaths(), input.Include)
+
+		// The runtime step is only created once
+		require.Equal(t, input, ctx.MiseRuntimeInput())
+		require.Len(t, ctx.Steps, 2)
+
+		// Packages added after the deploy is planned are still installed in the runtime step
+		miseStep.Default("python", "3.13")
+
+		version := func(v string) *string { return &v }
+		step, err := ctx.MiseRuntimeStepBuilder.Build(&BuildStepOptions{
+			ResolvedPackages: map[string]*resolver.ResolvedPackage{
+				"node":   {Name: "node", ResolvedVersion: version("22.10.0")},
+				"pnpm":   {Name: "pnpm", ResolvedVersion: version("9.12.0")},
+				"python": {Name: "python", ResolvedVersion: version("3.13.1")},
+			},
+			Caches: NewCacheContext(),
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, "true", step.Variables["MISE_NODE_COREPACK"])
+		require.Contains(t, step.Assets["mise.toml"], "node")
+		require.Contains(t, step.Assets["mise.toml"], "python")
+		require.NotContains(t, step.Assets["mise.toml"], "pnpm")
+
+		var installCommand plan.ExecCommand
+		for _, cmd := range step.Commands {
+			if exec, ok := cmd.(plan.ExecCommand); ok {
+				installCommand = exec
+			}
+		}
+		require.Equal(t, "install mise packages: node, python", installCommand.CustomName)
+	})
+}
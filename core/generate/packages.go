@@ -0,0 +1,218 @@
+package generate
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/unbindapp/railpack/core/plan"
+)
+
+// Backends of the typed packages of the config, e.g. `apt:ffmpeg`.
+// Packages without one of these backends are installed with mise
+const (
+	PackageBackendApt    = "apt"
+	PackageBackendGitHub = "github"
+	PackageBackendURL    = "url"
+	PackageBackendPip    = "pip"
+	PackageBackendNpm    = "npm"
+)
+
+const configPackageSource = "custom config"
+
+var (
+	packageBackends = []string{PackageBackendApt, PackageBackendGitHub, PackageBackendURL, PackageBackendPip, PackageBackendNpm}
+
+	binNameRegex  = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+	checksumRegex = regexp.MustCompile(`^sha256=([0-9a-fA-F]{64})$`)
+)
+
+// PackageSpec is a package of the config and the backend that installs it
+type PackageSpec struct {
+	Backend string
+	Name    string
+	Version string
+
+	// URL and Checksum are the file that a `url:` package downloads and its SHA-256 checksum
+	URL      string
+	Checksum string
+}
+
+// ParsePackageSpec parses a package of the config. The backend is the prefix of the name, e.g. `apt:ffmpeg`.
+// The version can also be part of the name, e.g. `github:cli/cli@v2.40.0`
+func ParsePackageSpec(name, version string) (PackageSpec, error) {
+	backend, rest, ok := strings.Cut(name, ":")
+	if !ok || !isPackageBackend(backend) {
+		return PackageSpec{Name: name, Version: version}, nil
+	}
+
+	spec := PackageSpec{Backend: backend, Name: rest, Version: strings.TrimSpace(version)}
+
+	if backend == PackageBackendURL {
+		return parseURLPackageSpec(spec)
+	}
+
+	// The first character can be an `@` of a scoped npm package
+	if i := strings.LastIndex(spec.Name, "@"); i > 0 {
+		nameVersion := spec.Name[i+1:]
+		if spec.Version != "" && spec.Version != nameVersion {
+			return spec, fmt.Errorf("package `%s` has version %s in its name and %s as its version. Only set one of them", name, nameVersion, spec.Version)
+		}
+		spec.Name, spec.Version = spec.Name[:i], nameVersion
+	}
+
+	if spec.Name == "" {
+		return spec, fmt.Errorf("package `%s` has no name", name)
+	}
+
+	if spec.Version == "" || spec.Version == "*" {
+		spec.Version = "latest"
+	}
+
+	if backend == PackageBackendGitHub {
+		owner, repo, ok := strings.Cut(spec.Name, "/")
+		if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+			return spec, fmt.Errorf("package `%s` is not a GitHub repository. Expected `github:owner/repo`", name)
+		}
+
+		// Release tags usually start with a `v`, but the versions of mise do not
+		if len(spec.Version) > 1 && spec.Version[0] == 'v' && spec.Version[1] >= '0' && spec.Version[1] <= '9' {
+			spec.Version = spec.Version[1:]
+		}
+	}
+
+	return spec, nil
+}
+
+// parseURLPackageSpec parses a `url:` package. The name is the name of the binary and the version is the URL
+// of the file, with the SHA-256 checksum as the fragment, e.g. `https://example.com/tool.tar.gz#sha256=...`
+func parseURLPackageSpec(spec PackageSpec) (PackageSpec, error) {
+	name := "url:" + spec.Name
+	if !binNameRegex.MatchString(spec.Name) {
+		return spec, fmt.Errorf("package `%s` must be named after its binary, e.g. `url:mytool`", name)
+	}
+
+	fileURL, err := url.Parse(spec.Version)
+	if err != nil || (fileURL.Scheme != "https" && fileURL.Scheme != "http") || fileURL.Host == "" {
+		return spec, fmt.Errorf("package `%s` has an invalid URL `%s`", name, spec.Version)
+	}
+
+	// The URL is used in a shell command
+	if strings.ContainsAny(spec.Version, "'\"`$\\ ") {
+		return spec, fmt.Errorf("package `%s` has a URL with quotes, spaces or `$`", name)
+	}
+
+	matches := checksumRegex.FindStringSubmatch(fileURL.Fragment)
+	if matches == nil {
+		return spec, fmt.Errorf("package `%s` has no checksum. Add it to the URL, e.g. `%s#sha256=<checksum>`", name, strings.Split(spec.Version, "#")[0])
+	}
+
+	fileURL.Fragment = ""
+	spec.URL = fileURL.String()
+	spec.Checksum = strings.ToLower(matches[1])
+
+	return spec, nil
+}
+
+func isPackageBackend(backend string) bool {
+	for _, b := range packageBackends {
+		if b == backend {
+			return true
+		}
+	}
+	return false
+}
+
+// applyConfigPackages installs the packages of the config with the step builder of their backend
+func (c *GenerateContext) applyConfigPackages(names []string) error {
+	miseStep := c.GetMiseStepBuilder()
+	aptPackages := []string{}
+
+	for _, name := range names {
+		spec, err := ParsePackageSpec(name, c.Config.Packages[name])
+		if err != nil {
+			return err
+		}
+
+		switch spec.Backend {
+		case PackageBackendApt:
+			c.Resolver.Fixed(name, spec.Version, spec.Version, configPackageSource)
+
+			aptPackage := spec.Name
+			if spec.Version != "latest" {
+				aptPackage += "=" + spec.Version
+			}
+
+			// The package is installed for the build and in the runtime image
+			miseStep.AddSupportingAptPackage(aptPackage)
+			aptPackages = append(aptPackages, aptPackage)
+
+		case PackageBackendGitHub:
+			// Release assets are downloaded with the ubi backend of mise
+			installStep := c.NewInstallBinStepBuilder("packages:github:" + spec.Name[strings.Index(spec.Name, "/")+1:])
+			ref := installStep.Default("ubi:"+spec.Name, spec.Version)
+			installStep.Version(ref, spec.Version, configPackageSource)
+			c.addBinPackage(installStep.Name(), installStep.GetOutputPaths())
+
+		case PackageBackendURL:
+			// The checksum identifies the file that is installed
+			c.Resolver.Fixed(name, spec.Version, "sha256:"+spec.Checksum, configPackageSource)
+
+			downloadStep := c.NewDownloadStepBuilder(spec.Name, spec.URL, spec.Checksum)
+			c.addBinPackage(downloadStep.Name(), downloadStep.GetOutputPaths())
+
+		case PackageBackendPip:
+			// The pipx backend of mise installs the tool into its own virtualenv, which needs python at runtime
+			miseStep.Default("python", "latest")
+			miseStep.DefaultBuildOnly("pipx", "latest")
+			ref := miseStep.Default("pipx:"+spec.Name, spec.Version)
+			miseStep.Version(ref, spec.Version, configPackageSource)
+
+		case PackageBackendNpm:
+			miseStep.Default("node", "latest")
+			ref := miseStep.Default("npm:"+spec.Name, spec.Version)
+			miseStep.Version(ref, spec.Version, configPackageSource)
+
+		default:
+			ref := miseStep.Default(spec.Name, spec.Version)
+			miseStep.Version(ref, spec.Version, configPackageSource)
+		}
+	}
+
+	if len(aptPackages) > 0 {
+		c.addRuntimeAptPackages(aptPackages)
+	}
+
+	return nil
+}
+
+// addBinPackage makes the binaries that a step installs available to the build and in the runtime image
+func (c *GenerateContext) addBinPackage(stepName string, outputPaths []string) {
+	input := plan.NewStepInput(stepName, plan.InputOptions{
+		Include: outputPaths,
+	})
+
+	c.GetMiseStepBuilder().AddInput(input)
+	c.Deploy.Inputs = append(c.Deploy.Inputs, input)
+}
+
+// addRuntimeAptPackages installs apt packages on top of the base image of the deploy
+func (c *GenerateContext) addRuntimeAptPackages(packages []string) {
+	aptStep := c.NewAptStepBuilder("apt")
+	aptStep.Packages = packages
+
+	// The first input of the deploy is the image that the other inputs are copied on top of
+	if len(c.Deploy.Inputs) > 0 && isBaseInput(c.Deploy.Inputs[0]) {
+		aptStep.AddInput(c.Deploy.Inputs[0])
+		c.Deploy.Inputs[0] = plan.NewStepInput(aptStep.Name())
+		return
+	}
+
+	aptStep.AddInput(plan.NewImageInput(plan.RAILPACK_RUNTIME_IMAGE))
+	c.Deploy.Inputs = append([]plan.Input{plan.NewStepInput(aptStep.Name())}, c.Deploy.Inputs...)
+}
+
+func isBaseInput(input plan.Input) bool {
+	return !input.Local && !input.Spread && len(input.Include) == 0 && len(input.Exclude) == 0
+}
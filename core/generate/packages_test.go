@@ -0,0 +1,155 @@
+package generate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/unbindapp/railpack/core/plan"
+)
+
+const testChecksum = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+func TestParsePackageSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		want    PackageSpec
+		wantErr string
+	}{
+		{name: "node", version: "22", want: PackageSpec{Name: "node", Version: "22"}},
+		{name: "aqua:cli/cli", version: "2", want: PackageSpec{Name: "aqua:cli/cli", Version: "2"}},
+		{name: "apt:ffmpeg", version: "*", want: PackageSpec{Backend: "apt", Name: "ffmpeg", Version: "latest"}},
+		{name: "apt:ffmpeg", version: "7:5.1.6-0+deb12u1", want: PackageSpec{Backend: "apt", Name: "ffmpeg", Version: "7:5.1.6-0+deb12u1"}},
+		{name: "github:cli/cli@v2.40.0", want: PackageSpec{Backend: "github", Name: "cli/cli", Version: "2.40.0"}},
+		{name: "github:cli/cli", version: "latest", want: PackageSpec{Backend: "github", Name: "cli/cli", Version: "latest"}},
+		{name: "pip:black", version: "24", want: PackageSpec{Backend: "pip", Name: "black", Version: "24"}},
+		{name: "npm:@biomejs/biome@1.9", want: PackageSpec{Backend: "npm", Name: "@biomejs/biome", Version: "1.9"}},
+		{name: "npm:@biomejs/biome", want: PackageSpec{Backend: "npm", Name: "@biomejs/biome", Version: "latest"}},
+		{
+			name:    "url:mytool",
+			version: "https://example.com/mytool-1.2.tar.gz#sha256=" + strings.ToUpper(testChecksum),
+			want: PackageSpec{
+				Backend:  "url",
+				Name:     "mytool",
+				Version:  "https://example.com/mytool-1.2.tar.gz#sha256=" + strings.ToUpper(testChecksum),
+				URL:      "https://example.com/mytool-1.2.tar.gz",
+				Checksum: testChecksum,
+			},
+		},
+		{name: "github:cli", wantErr: "is not a GitHub repository"},
+		{name: "github:cli/cli@2", version: "3", wantErr: "Only set one of them"},
+		{name: "apt:", wantErr: "has no name"},
+		{name: "url:mytool", version: "https://example.com/mytool", wantErr: "has no checksum"},
+		{name: "url:mytool", version: "ftp://example.com/mytool#sha256=" + testChecksum, wantErr: "invalid URL"},
+		{name: "url:mytool", version: "https://example.com/$(id)#sha256=" + testChecksum, wantErr: "quotes, spaces or `$`"},
+		{name: "url:my/tool", version: "https://example.com/mytool#sha256=" + testChecksum, wantErr: "must be named after its binary"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := ParsePackageSpec(tt.name, tt.version)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, spec)
+		})
+	}
+}
+
+func TestApplyConfigPackages(t *testing.T) {
+	ctx := CreateTestContext(t, "../../examples/node-npm")
+	ctx.Config.Packages = map[string]string{
+		"python":          "3.13",
+		"apt:ffmpeg":      "*",
+		"github:cli/cli":  "v2.40.0",
+		"url:mytool":      "https://example.com/mytool.tar.gz#sha256=" + testChecksum,
+		"pip:black":       "24",
+		"npm:prettier":    "3",
+		"apt:imagemagick": "latest",
+	}
+
+	require.NoError(t, ctx.applyConfig())
+	miseStep := ctx.GetMiseStepBuilder()
+
+	// pip and npm packages are installed with the mise backends
+	names := []string{}
+	for _, pkg := range miseStep.MisePackages {
+		names = append(names, pkg.Name)
+	}
+	require.ElementsMatch(t, []string{"python", "pipx", "pipx:black", "node", "npm:prettier"}, names)
+	require.Equal(t, map[string]bool{"pipx": true}, miseStep.BuildOnlyPackages)
+	require.Equal(t, "3", ctx.Resolver.Get("npm:prettier").Version)
+
+	// Apt packages are installed for the build and on top of the runtime image
+	require.Subset(t, miseStep.SupportingAptPackages, []string{"ffmpeg", "imagemagick"})
+	require.Equal(t, plan.NewStepInput("packages:apt"), ctx.Deploy.Inputs[0])
+	aptStep := (*ctx.GetStepByName("packages:apt")).(*AptStepBuilder)
+	require.Equal(t, []string{"ffmpeg", "imagemagick"}, aptStep.Packages)
+	require.Equal(t, []plan.Input{plan.NewImageInput(plan.RAILPACK_RUNTIME_IMAGE)}, aptStep.Inputs)
+	require.Equal(t, "latest", ctx.Resolver.Get("apt:ffmpeg").FixedVersion)
+	require.Equal(t, "sha256:"+testChecksum, ctx.Resolver.Get("url:mytool").FixedVersion)
+
+	// Binaries are copied into the mise step and the deploy
+	githubInput := plan.NewStepInput("packages:github:cli", plan.InputOptions{Include: []string{"/railpack/cli"}})
+	urlInput := plan.NewStepInput("packages:url:mytool", plan.InputOptions{Include: []string{"/railpack/mytool"}})
+	require.Equal(t, []plan.Input{githubInput, urlInput}, miseStep.Inputs)
+	require.Contains(t, ctx.Deploy.Inputs, githubInput)
+	require.Contains(t, ctx.Deploy.Inputs, urlInput)
+	require.Equal(t, "2.40.0", ctx.Resolver.Get("ubi:cli/cli").Version)
+
+	step, err := (*ctx.GetStepByName("packages:url:mytool")).Build(&BuildStepOptions{Caches: NewCacheContext()})
+	require.NoError(t, err)
+	require.Equal(t, plan.NewExecCommand(
+		"sh -c "+shellQuote("curl -fsSL -o '/tmp/railpack-download-mytool.tar.gz' 'https://example.com/mytool.tar.gz' && echo '"+testChecksum+"  /tmp/railpack-download-mytool.tar.gz' | sha256sum -c - && mkdir -p '/railpack/mytool' && tar -xzf '/tmp/railpack-download-mytool.tar.gz' -C '/railpack/mytool'"),
+		plan.ExecOptions{CustomName: "download mytool"},
+	), step.Commands[0])
+}
+
+func TestDownloadStepPresignedURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		install string
+	}{
+		{
+			url:     "https://example.com/mytool.zip?X-Amz-Expires=60&X-Amz-Signature=abc",
+			install: "unzip -q '/tmp/railpack-download-mytool.zip' -d '/railpack/mytool'",
+		},
+		{
+			url:     "https://example.com/download?file=mytool.tar.gz&sig=a;b",
+			install: "install -m 755 '/tmp/railpack-download-mytool' '/railpack/mytool/mytool'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			ctx := CreateTestContext(t, "../../examples/node-npm")
+			downloadStep := ctx.NewDownloadStepBuilder("mytool", tt.url, testChecksum)
+
+			step, err := downloadStep.Build(&BuildStepOptions{Caches: NewCacheContext()})
+			require.NoError(t, err)
+
+			// The file name comes from the path of the URL, and the URL is a single quoted argument of curl
+			file := "/tmp/railpack-download-mytool" + archiveExtension(strings.Split(tt.url, "?")[0])
+			require.Equal(t, plan.NewExecCommand(
+				"sh -c "+shellQuote("curl -fsSL -o '"+file+"' '"+tt.url+"' && echo '"+testChecksum+"  "+file+"' | sha256sum -c - && mkdir -p '/railpack/mytool' && "+tt.install),
+				plan.ExecOptions{CustomName: "download mytool"},
+			), step.Commands[0])
+		})
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	require.Equal(t, `'a&b;c'`, shellQuote("a&b;c"))
+	require.Equal(t, `'it'\''s'`, shellQuote("it's"))
+}
+
+func TestApplyConfigPackagesError(t *testing.T) {
+	ctx := CreateTestContext(t, "../../examples/node-npm")
+	ctx.Config.Packages = map[string]string{"url:mytool": "https://example.com/mytool"}
+
+	require.ErrorContains(t, ctx.applyConfig(), "package `url:mytool` has no checksum")
+}
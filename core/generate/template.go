@@ -3,6 +3,9 @@ package generate
 import (
 	"bytes"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 	"text/template"
 )
 
@@ -31,18 +34,55 @@ func (c *GenerateContext) TemplateFiles(potentialFiles []string, defaultContents
 		}
 	}
 
-	tmpl, err := template.New(filename).Parse(contents)
+	rendered, err := renderTemplate(filename, contents, data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse template: %w", err)
+		return nil, err
+	}
+
+	return &TemplateFileResult{
+		Filename: filename,
+		Contents: rendered,
+	}, nil
+}
+
+// templateAssetFile reads a file in the app and renders it with the env vars and metadata of the build.
+// Secrets are never available to the template since the result is saved in the plan and image
+func (c *GenerateContext) templateAssetFile(filename string) (string, error) {
+	contents, err := c.App.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+
+	env := maps.Clone(c.Config.BuildArgs)
+	if env == nil {
+		env = map[string]string{}
+	}
+	for name, value := range c.Env.Variables {
+		if !slices.Contains(c.Secrets, name) || strings.HasPrefix(name, "RAILPACK_") {
+			env[name] = value
+		}
+	}
+
+	data := map[string]interface{}{
+		"Env":      env,
+		"Provider": c.ProviderName,
+		"Metadata": c.Metadata.Properties,
+	}
+
+	// Unset variables render as empty strings
+	return renderTemplate(filename, contents, data, "missingkey=zero")
+}
+
+func renderTemplate(name, contents string, data map[string]interface{}, options ...string) (string, error) {
+	tmpl, err := template.New(name).Option(options...).Parse(contents)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
 	}
 
 	var buf bytes.Buffer
 	if err := tmpl.Execute(&buf, data); err != nil {
-		return nil, fmt.Errorf("failed to execute template: %w", err)
+		return "", fmt.Errorf("failed to execute template: %w", err)
 	}
 
-	return &TemplateFileResult{
-		Filename: filename,
-		Contents: buf.String(),
-	}, nil
+	return buf.String(), nil
 }
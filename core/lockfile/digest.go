@@ -0,0 +1,25 @@
+package lockfile
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/containerd/containerd/v2/core/remotes/docker"
+	"github.com/distribution/reference"
+)
+
+// ResolveImageDigest looks up the digest of an image reference in its registry
+func ResolveImageDigest(ctx context.Context, image string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", fmt.Errorf("invalid image %s: %w", image, err)
+	}
+	named = reference.TagNameOnly(named)
+
+	_, desc, err := docker.NewResolver(docker.ResolverOptions{}).Resolve(ctx, named.String())
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve digest of image %s: %w", image, err)
+	}
+
+	return desc.Digest.String(), nil
+}
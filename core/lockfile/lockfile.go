@@ -0,0 +1,191 @@
+package lockfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/unbindapp/railpack/core/app"
+	"github.com/unbindapp/railpack/core/plan"
+	"github.com/unbindapp/railpack/core/resolver"
+)
+
+const (
+	// FileName is the name of the lockfile in the root of the app
+	FileName = "railpack.lock"
+
+	// Version is the version of the lockfile format
+	Version = 1
+)
+
+// Lockfile pins the resolved package versions and image digests of an app so that rebuilds are deterministic
+type Lockfile struct {
+	Version int `json:"version"`
+
+	// Packages are the resolved versions of the mise packages, keyed by name
+	Packages map[string]*resolver.ResolvedPackage `json:"packages"`
+
+	// Images are the digests of the images used in the plan, keyed by image reference
+	Images map[string]string `json:"images,omitempty"`
+}
+
+// New creates a lockfile from resolved packages and image digests
+func New(packages map[string]*resolver.ResolvedPackage, images map[string]string) *Lockfile {
+	return &Lockfile{
+		Version:  Version,
+		Packages: packages,
+		Images:   images,
+	}
+}
+
+// Read reads the lockfile of the app. Returns nil if the app does not have one
+func Read(a *app.App) (*Lockfile, error) {
+	if !a.HasMatch(FileName) {
+		return nil, nil
+	}
+
+	lockfile := &Lockfile{}
+	if err := a.ReadJSON(FileName, lockfile); err != nil {
+		return nil, err
+	}
+
+	if lockfile.Version > Version {
+		return nil, fmt.Errorf("%s has version %d, but this version of Railpack only supports up to version %d", FileName, lockfile.Version, Version)
+	}
+
+	if lockfile.Packages == nil {
+		lockfile.Packages = map[string]*resolver.ResolvedPackage{}
+	}
+
+	return lockfile, nil
+}
+
+// Marshal serializes the lockfile with sorted keys and a trailing newline so that it diffs cleanly
+func (l *Lockfile) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
+
+// PinImages replaces the image references in the plan with their locked digests.
+// When frozen, every image in the plan must be locked
+func (l *Lockfile) PinImages(buildPlan *plan.BuildPlan, frozen bool) error {
+	missing := []string{}
+
+	pin := func(input *plan.Input) {
+		if input.Image == "" || strings.Contains(input.Image, "@") {
+			return
+		}
+
+		digest, ok := l.Images[input.Image]
+		if !ok {
+			if !slices.Contains(missing, input.Image) {
+				missing = append(missing, input.Image)
+			}
+			return
+		}
+
+		input.Image = fmt.Sprintf("%s@%s", input.Image, digest)
+	}
+
+	for i := range buildPlan.Steps {
+		for j := range buildPlan.Steps[i].Inputs {
+			pin(&buildPlan.Steps[i].Inputs[j])
+		}
+	}
+
+	for i := range buildPlan.Deploy.Inputs {
+		pin(&buildPlan.Deploy.Inputs[i])
+	}
+
+	if frozen && len(missing) > 0 {
+		return fmt.Errorf("images are not locked: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// PlanImages returns the images used by the plan. Images that are pinned to a digest are split into the
+// reference and the digest. Unpinned images have an empty digest
+func PlanImages(buildPlan *plan.BuildPlan) map[string]string {
+	images := map[string]string{}
+
+	add := func(input plan.Input) {
+		if input.Image == "" {
+			return
+		}
+
+		ref, digest, _ := strings.Cut(input.Image, "@")
+		if images[ref] == "" {
+			images[ref] = digest
+		}
+	}
+
+	for _, step := range buildPlan.Steps {
+		for _, input := range step.Inputs {
+			add(input)
+		}
+	}
+
+	for _, input := range buildPlan.Deploy.Inputs {
+		add(input)
+	}
+
+	return images
+}
+
+// Diff describes the changes from the previous lockfile to this one
+func (l *Lockfile) Diff(previous *Lockfile) []string {
+	changes := []string{}
+
+	version := func(pkg *resolver.ResolvedPackage) string {
+		if pkg == nil || pkg.ResolvedVersion == nil {
+			return ""
+		}
+		return *pkg.ResolvedVersion
+	}
+
+	previousPackages := map[string]*resolver.ResolvedPackage{}
+	previousImages := map[string]string{}
+	if previous != nil {
+		previousPackages = previous.Packages
+		previousImages = previous.Images
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(l.Packages)) {
+		current, old := version(l.Packages[name]), version(previousPackages[name])
+		switch {
+		case old == "":
+			changes = append(changes, fmt.Sprintf("+ %s %s", name, current))
+		case old != current:
+			changes = append(changes, fmt.Sprintf("~ %s %s -> %s", name, old, current))
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(previousPackages)) {
+		if _, ok := l.Packages[name]; !ok {
+			changes = append(changes, fmt.Sprintf("- %s %s", name, version(previousPackages[name])))
+		}
+	}
+
+	for _, ref := range slices.Sorted(maps.Keys(l.Images)) {
+		if old, ok := previousImages[ref]; !ok {
+			changes = append(changes, fmt.Sprintf("+ %s", ref))
+		} else if old != l.Images[ref] {
+			changes = append(changes, fmt.Sprintf("~ %s", ref))
+		}
+	}
+
+	for _, ref := range slices.Sorted(maps.Keys(previousImages)) {
+		if _, ok := l.Images[ref]; !ok {
+			changes = append(changes, fmt.Sprintf("- %s", ref))
+		}
+	}
+
+	return changes
+}
@@ -0,0 +1,98 @@
+package lockfile
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+	"github.com/unbindapp/railpack/core/app"
+	"github.com/unbindapp/railpack/core/plan"
+	"github.com/unbindapp/railpack/core/resolver"
+)
+
+func resolved(name, requested, version string) *resolver.ResolvedPackage {
+	return &resolver.ResolvedPackage{Name: name, RequestedVersion: &requested, ResolvedVersion: &version, Source: "test"}
+}
+
+func TestRead(t *testing.T) {
+	lock, err := Read(app.NewAppFromFS("test", fstest.MapFS{}))
+	require.NoError(t, err)
+	require.Nil(t, lock)
+
+	lock, err = Read(app.NewAppFromFS("test", fstest.MapFS{
+		FileName: {Data: []byte(`{
+			"version": 1,
+			"packages": {"node": {"name": "node", "requestedVersion": "22", "resolvedVersion": "22.9.0", "source": "test"}},
+			"images": {"ghcr.io/railwayapp/railpack-builder:latest": "sha256:abc"}
+		}`)},
+	}))
+	require.NoError(t, err)
+	require.Equal(t, New(
+		map[string]*resolver.ResolvedPackage{"node": resolved("node", "22", "22.9.0")},
+		map[string]string{"ghcr.io/railwayapp/railpack-builder:latest": "sha256:abc"},
+	), lock)
+
+	_, err = Read(app.NewAppFromFS("test", fstest.MapFS{
+		FileName: {Data: []byte(`{"version": 2}`)},
+	}))
+	require.Error(t, err)
+}
+
+func TestPinImages(t *testing.T) {
+	newPlan := func() *plan.BuildPlan {
+		buildPlan := plan.NewBuildPlan()
+		buildPlan.AddStep(plan.Step{Name: "install", Inputs: []plan.Input{plan.NewImageInput(plan.RAILPACK_BUILDER_IMAGE)}})
+		buildPlan.AddStep(plan.Step{Name: "build", Inputs: []plan.Input{plan.NewStepInput("install")}})
+		buildPlan.Deploy.Inputs = []plan.Input{
+			plan.NewImageInput(plan.RAILPACK_RUNTIME_IMAGE),
+			plan.NewImageInput("node:22@sha256:ccc"),
+		}
+		return buildPlan
+	}
+
+	lock := New(nil, map[string]string{plan.RAILPACK_BUILDER_IMAGE: "sha256:aaa"})
+
+	buildPlan := newPlan()
+	require.Error(t, lock.PinImages(buildPlan, true))
+
+	require.NoError(t, lock.PinImages(buildPlan, false))
+	require.Equal(t, plan.RAILPACK_BUILDER_IMAGE+"@sha256:aaa", buildPlan.Steps[0].Inputs[0].Image)
+	require.Equal(t, plan.RAILPACK_RUNTIME_IMAGE, buildPlan.Deploy.Inputs[0].Image)
+
+	require.Equal(t, map[string]string{
+		plan.RAILPACK_BUILDER_IMAGE: "sha256:aaa",
+		plan.RAILPACK_RUNTIME_IMAGE: "",
+		"node:22":                   "sha256:ccc",
+	}, PlanImages(buildPlan))
+
+	lock.Images[plan.RAILPACK_RUNTIME_IMAGE] = "sha256:bbb"
+	require.NoError(t, lock.PinImages(newPlan(), true))
+}
+
+func TestDiff(t *testing.T) {
+	previous := New(
+		map[string]*resolver.ResolvedPackage{
+			"node":   resolved("node", "22", "22.3.0"),
+			"python": resolved("python", "3.12", "3.12.1"),
+		},
+		map[string]string{plan.RAILPACK_BUILDER_IMAGE: "sha256:aaa"},
+	)
+
+	lock := New(
+		map[string]*resolver.ResolvedPackage{
+			"node": resolved("node", "22", "22.9.0"),
+			"bun":  resolved("bun", "latest", "1.2.0"),
+		},
+		map[string]string{plan.RAILPACK_BUILDER_IMAGE: "sha256:bbb"},
+	)
+
+	require.Equal(t, []string{
+		"+ bun 1.2.0",
+		"~ node 22.3.0 -> 22.9.0",
+		"- python 3.12.1",
+		"~ " + plan.RAILPACK_BUILDER_IMAGE,
+	}, lock.Diff(previous))
+
+	require.Empty(t, lock.Diff(lock))
+	require.Len(t, lock.Diff(nil), 3)
+}
@@ -3,7 +3,11 @@ package mise
 import (
 	"archive/tar"
 	"archive/zip"
+	"bufio"
 	"compress/gzip"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
@@ -19,8 +23,18 @@ import (
 const (
 	miseVersion       = "2025.3.0"
 	githubReleaseBase = "https://github.com/jdx/mise/releases/download"
+
+	// MirrorEnvVar is a base URL to download mise from instead of GitHub. It must have the layout of the releases,
+	// e.g. `<mirror>/v2025.3.0/mise-v2025.3.0-linux-x64.tar.gz`
+	MirrorEnvVar = "RAILPACK_MISE_MIRROR"
 )
 
+//go:embed checksums.txt
+var embeddedChecksums string
+
+// checksums are the SHA256 checksums of the mise release assets, by asset name
+var checksums = parseChecksums(embeddedChecksums)
+
 // getBinaryName returns the name of the binary based on the operating system
 func getBinaryName() string {
 	if runtime.GOOS == "windows" {
@@ -29,35 +43,39 @@ func getBinaryName() string {
 	return fmt.Sprintf("mise-%s", miseVersion)
 }
 
+// platforms are the release platforms of mise for each supported OS and architecture
+var platforms = []struct {
+	goos, goarch, platform string
+}{
+	{"linux", "amd64", "linux-x64"},
+	{"linux", "arm64", "linux-arm64"},
+	{"linux", "arm", "linux-armv7"},
+	{"darwin", "amd64", "macos-x64"},
+	{"darwin", "arm64", "macos-arm64"},
+	{"windows", "amd64", "windows-x64"},
+	{"windows", "arm64", "windows-arm64"},
+}
+
 // getAssetName returns the platform-specific asset name
 func getAssetName() (string, error) {
-	var platform string
-
-	switch {
-	case runtime.GOOS == "linux" && runtime.GOARCH == "amd64":
-		platform = "linux-x64"
-	case runtime.GOOS == "linux" && runtime.GOARCH == "arm64":
-		platform = "linux-arm64"
-	case runtime.GOOS == "linux" && runtime.GOARCH == "arm":
-		platform = "linux-armv7"
-	case runtime.GOOS == "darwin" && runtime.GOARCH == "amd64":
-		platform = "macos-x64"
-	case runtime.GOOS == "darwin" && runtime.GOARCH == "arm64":
-		platform = "macos-arm64"
-	case runtime.GOOS == "windows" && runtime.GOARCH == "amd64":
-		platform = "windows-x64"
-	case runtime.GOOS == "windows" && runtime.GOARCH == "arm64":
-		platform = "windows-arm64"
-	default:
-		return "", fmt.Errorf("unsupported platform: %s %s", runtime.GOOS, runtime.GOARCH)
-	}
-
-	extension := "tar.gz"
-	if runtime.GOOS == "windows" {
-		extension = "zip"
+	return assetNameFor(runtime.GOOS, runtime.GOARCH)
+}
+
+func assetNameFor(goos, goarch string) (string, error) {
+	for _, p := range platforms {
+		if p.goos != goos || p.goarch != goarch {
+			continue
+		}
+
+		extension := "tar.gz"
+		if goos == "windows" {
+			extension = "zip"
+		}
+
+		return fmt.Sprintf("mise-v%s-%s.%s", miseVersion, p.platform, extension), nil
 	}
 
-	return fmt.Sprintf("mise-v%s-%s.%s", miseVersion, platform, extension), nil
+	return "", fmt.Errorf("unsupported platform: %s %s", goos, goarch)
 }
 
 // getBinaryPath returns the full path to the binary
@@ -99,16 +117,22 @@ func downloadAndInstall(cacheDir string) error {
 		return err
 	}
 
-	url := fmt.Sprintf("%s/v%s/%s", githubReleaseBase, miseVersion, assetName)
+	base := releaseBase()
+	url := fmt.Sprintf("%s/v%s/%s", base, miseVersion, assetName)
 	binaryPath := getBinaryPath(cacheDir)
 
+	expectedChecksum, err := getExpectedChecksum(assetName)
+	if err != nil {
+		return err
+	}
+
 	log.Debugf("Downloading mise from %s", url)
 
-	resp, err := http.Get(url)
+	body, err := download(url)
 	if err != nil {
-		return fmt.Errorf("failed to download mise: %w", err)
+		return err
 	}
-	defer resp.Body.Close()
+	defer body.Close()
 
 	// Create temporary directory
 	tempDir, err := os.MkdirTemp("", "mise-install")
@@ -123,12 +147,18 @@ func downloadAndInstall(cacheDir string) error {
 		return fmt.Errorf("failed to create archive file: %w", err)
 	}
 
-	if _, err := io.Copy(f, resp.Body); err != nil {
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, hash), body); err != nil {
 		f.Close()
 		return fmt.Errorf("failed to save archive: %w", err)
 	}
 	f.Close()
 
+	// The archive is only extracted and run once it matches the checksum
+	if checksum := hex.EncodeToString(hash.Sum(nil)); checksum != expectedChecksum {
+		return fmt.Errorf("checksum mismatch for %s downloaded from %s: expected sha256 %s, got %s. The download is corrupted or was modified", assetName, url, expectedChecksum, checksum)
+	}
+
 	if runtime.GOOS == "windows" {
 		err = extractZip(archivePath, binaryPath)
 	} else {
@@ -147,6 +177,89 @@ func downloadAndInstall(cacheDir string) error {
 	return nil
 }
 
+// releaseBase returns the base URL of the mise releases
+func releaseBase() string {
+	if mirror := os.Getenv(MirrorEnvVar); mirror != "" {
+		return strings.TrimSuffix(mirror, "/")
+	}
+	return githubReleaseBase
+}
+
+// checksumsBase is where the SHASUMS256.txt of a release is fetched from when checksums.txt has no entry for an asset
+var checksumsBase = githubReleaseBase
+
+// getExpectedChecksum returns the SHA256 checksum of a release asset. Embedded checksums are used first. Without one,
+// the checksum is fetched from the GitHub release, but never from a mirror, since a modified mirror could serve a
+// matching checksum
+func getExpectedChecksum(assetName string) (string, error) {
+	if checksum, ok := checksums[assetName]; ok {
+		return checksum, nil
+	}
+
+	if os.Getenv(MirrorEnvVar) != "" {
+		return "", fmt.Errorf("no embedded checksum for %s, so it cannot be downloaded from %s. Run `mise run update-mise-checksums` and rebuild railpack", assetName, MirrorEnvVar)
+	}
+
+	log.Warnf("No embedded checksum for %s, using the checksum of the GitHub release. Run `mise run update-mise-checksums` and rebuild railpack", assetName)
+
+	url := fmt.Sprintf("%s/v%s/SHASUMS256.txt", checksumsBase, miseVersion)
+	body, err := download(url)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read checksums from %s: %w", url, err)
+	}
+
+	checksum, ok := parseChecksums(string(data))[assetName]
+	if !ok {
+		return "", fmt.Errorf("no checksum for %s in %s", assetName, url)
+	}
+
+	return checksum, nil
+}
+
+// parseChecksums parses checksums in the format of `sha256sum`. Lines starting with # are skipped
+func parseChecksums(data string) map[string]string {
+	checksums := map[string]string{}
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		name := strings.TrimPrefix(strings.TrimPrefix(fields[1], "*"), "./")
+		checksums[name] = strings.ToLower(fields[0])
+	}
+
+	return checksums
+}
+
+// download gets the body of a URL. Responses other than 200 are errors
+func download(url string) (io.ReadCloser, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download %s: %s", url, resp.Status)
+	}
+
+	return resp.Body, nil
+}
+
 func extractTarGz(archivePath, binaryPath string) error {
 	f, err := os.Open(archivePath)
 	if err != nil {
@@ -0,0 +1,115 @@
+package mise
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRelease creates a release archive with a mise binary that only prints its version
+func fakeRelease(t *testing.T) []byte {
+	t.Helper()
+
+	script := fmt.Sprintf("#!/bin/sh\necho \"%s linux-x64\"\n", miseVersion)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "mise/bin/mise", Mode: 0755, Size: int64(len(script))}))
+	_, err := tw.Write([]byte(script))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	return buf.Bytes()
+}
+
+func TestInstallFromMirror(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake release is a shell script")
+	}
+
+	assetName, err := getAssetName()
+	require.NoError(t, err)
+
+	archive := fakeRelease(t)
+	sum := sha256.Sum256(archive)
+	checksum := hex.EncodeToString(sum[:])
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case fmt.Sprintf("/mise/v%s/%s", miseVersion, assetName):
+			_, _ = w.Write(archive)
+		case fmt.Sprintf("/github/v%s/SHASUMS256.txt", miseVersion):
+			_, _ = fmt.Fprintf(w, "%s  ./%s\n", checksum, assetName)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv(MirrorEnvVar, server.URL+"/mise/")
+
+	original := checksums
+	t.Cleanup(func() { checksums = original })
+
+	// Embedded checksums are used first
+	checksums = map[string]string{assetName: checksum}
+	binaryPath, err := ensureInstalled(t.TempDir())
+	require.NoError(t, err)
+	require.FileExists(t, binaryPath)
+
+	checksums = map[string]string{assetName: "0000"}
+	_, err = ensureInstalled(t.TempDir())
+	require.ErrorContains(t, err, "checksum mismatch")
+
+	// Without an embedded checksum, nothing is downloaded from a mirror
+	checksums = map[string]string{}
+	_, err = ensureInstalled(t.TempDir())
+	require.ErrorContains(t, err, "no embedded checksum")
+
+	// Without a mirror, the checksum of the GitHub release is used
+	originalBase := checksumsBase
+	t.Cleanup(func() { checksumsBase = originalBase })
+	checksumsBase = server.URL + "/github"
+	t.Setenv(MirrorEnvVar, "")
+	expected, err := getExpectedChecksum(assetName)
+	require.NoError(t, err)
+	require.Equal(t, checksum, expected)
+
+	_, err = getExpectedChecksum("mise-missing.tar.gz")
+	require.ErrorContains(t, err, "no checksum for mise-missing.tar.gz")
+	t.Setenv(MirrorEnvVar, server.URL+"/mise/")
+
+	checksums = map[string]string{assetName: checksum}
+	t.Setenv(MirrorEnvVar, server.URL+"/missing")
+	_, err = ensureInstalled(t.TempDir())
+	require.ErrorContains(t, err, "404")
+}
+
+func TestParseChecksums(t *testing.T) {
+	parsed := parseChecksums("# comment\n\nABC123  ./mise-v1-linux-x64.tar.gz\ndef456 *mise-v1-windows-x64.zip\ninvalid\n")
+	require.Equal(t, map[string]string{
+		"mise-v1-linux-x64.tar.gz": "abc123",
+		"mise-v1-windows-x64.zip":  "def456",
+	}, parsed)
+}
+
+// TestEmbeddedChecksums checks that checksums.txt has a SHA256 checksum for the release asset of every platform
+func TestEmbeddedChecksums(t *testing.T) {
+	for _, p := range platforms {
+		assetName, err := assetNameFor(p.goos, p.goarch)
+		require.NoError(t, err)
+		require.Contains(t, checksums, assetName, "run `mise run update-mise-checksums` after changing the mise version")
+		require.Regexp(t, "^[0-9a-f]{64}$", checksums[assetName])
+	}
+}
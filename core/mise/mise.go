@@ -26,9 +26,29 @@ type Mise struct {
 
 const (
 	ErrMiseGetLatestVersion = "failed to resolve version %s of %s"
+
+	// BinEnvVar is the path of a pre-installed mise binary to use instead of downloading mise
+	BinEnvVar = "RAILPACK_MISE_BIN"
 )
 
 func New(cacheDir string) (*Mise, error) {
+	if binaryPath := os.Getenv(BinEnvVar); binaryPath != "" {
+		if _, err := os.Stat(binaryPath); err != nil {
+			return nil, fmt.Errorf("failed to find mise binary from %s: %w", BinEnvVar, err)
+		}
+
+		// The cache directory is still used for the mise cache and data
+		if err := os.MkdirAll(cacheDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create cache directory: %w", err)
+		}
+
+		log.Debugf("Using mise executable at %s from %s", binaryPath, BinEnvVar)
+		return &Mise{
+			binaryPath: binaryPath,
+			cacheDir:   cacheDir,
+		}, nil
+	}
+
 	binaryPath, err := ensureInstalled(cacheDir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to ensure mise is installed: %w", err)
@@ -40,19 +60,19 @@ func New(cacheDir string) (*Mise, error) {
 	}, nil
 }
 
-// GetLatestVersion gets the latest version of a package matching the version constraint
-func (m *Mise) GetLatestVersion(pkg, version string) (string, error) {
+// LatestVersion gets the latest version of a package matching the version constraint
+func (m *Mise) LatestVersion(pkg, version string) (string, error) {
 	_, unlock, err := m.createAndLock(pkg)
 	if err != nil {
 		return "", err
 	}
 	defer unlock()
 
-	query := fmt.Sprintf("%s@%s", pkg, utils.ExtractSemverVersion(version))
+	query := fmt.Sprintf("%s@%s", pkg, versionQuery(version))
 	output, err := m.runCmd("latest", query)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found in mise tool registry") {
-			return "", fmt.Errorf("package `%s` not available in Mise. Try installing as apt package instead", pkg)
+			return "", fmt.Errorf("package `%s` not available in Mise. Try installing it with `apt:%s`, `github:`, `url:`, `pip:` or `npm:` in packages instead", pkg, pkg)
 		}
 
 		return "", err
@@ -66,14 +86,19 @@ func (m *Mise) GetLatestVersion(pkg, version string) (string, error) {
 	return latestVersion, nil
 }
 
-func (m *Mise) GetAllVersions(pkg, version string) ([]string, error) {
+func (m *Mise) AllVersions(pkg, version string) ([]string, error) {
 	_, unlock, err := m.createAndLock(pkg)
 	if err != nil {
 		return nil, err
 	}
 	defer unlock()
 
-	query := fmt.Sprintf("%s@%s", pkg, utils.ExtractSemverVersion(version))
+	// Without a version prefix, all versions of the package are listed
+	query := pkg
+	if prefix := versionQuery(version); prefix != "" {
+		query = fmt.Sprintf("%s@%s", pkg, prefix)
+	}
+
 	output, err := m.runCmd("ls-remote", query)
 	if err != nil {
 		return nil, err
@@ -96,6 +121,44 @@ func (m *Mise) GetAllVersions(pkg, version string) ([]string, error) {
 	return versions, nil
 }
 
+// versionQuery returns the version that is passed to mise. Versions are reduced to their numeric part, and aliases
+// like `lts` or `lts/iron` are passed as is, since mise resolves them
+func versionQuery(version string) string {
+	version = strings.TrimSpace(version)
+	if prefix := utils.ExtractSemverVersion(version); prefix != "" {
+		return prefix
+	}
+
+	if version == "latest" || version == "*" {
+		return ""
+	}
+
+	return version
+}
+
+// ListVersions lists all available versions of a package
+func (m *Mise) ListVersions(pkg string) ([]string, error) {
+	_, unlock, err := m.createAndLock(pkg)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	output, err := m.runCmd("ls-remote", pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if version := strings.TrimSpace(line); version != "" {
+			versions = append(versions, version)
+		}
+	}
+
+	return versions, nil
+}
+
 // runCmd runs a mise command with the given arguments
 func (m *Mise) runCmd(args ...string) (string, error) {
 	cacheDir := filepath.Join(m.cacheDir, "cache")
@@ -48,17 +48,17 @@ func TestMistGetLatestVersion(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := mise.GetLatestVersion(tt.runtime, tt.version)
+			got, err := mise.LatestVersion(tt.runtime, tt.version)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("GetLatestVersion() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("LatestVersion() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if !tt.wantErr {
 				if tt.wantPrefix != "" && !strings.HasPrefix(got, tt.wantPrefix) {
-					t.Errorf("GetLatestVersion() got = %v, want prefix %v", got, tt.wantPrefix)
+					t.Errorf("LatestVersion() got = %v, want prefix %v", got, tt.wantPrefix)
 				}
 				if got == "" {
-					t.Error("GetLatestVersion() got empty version")
+					t.Error("LatestVersion() got empty version")
 				}
 			}
 		})
@@ -112,9 +112,9 @@ func TestMiseGetAllVersions(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := mise.GetAllVersions(tt.runtime, tt.version)
+			got, err := mise.AllVersions(tt.runtime, tt.version)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("GetAllVersions() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("AllVersions() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 
@@ -124,3 +124,12 @@ func TestMiseGetAllVersions(t *testing.T) {
 		})
 	}
 }
+
+func TestVersionQuery(t *testing.T) {
+	require.Equal(t, "22", versionQuery("22"))
+	require.Equal(t, "3.12", versionQuery("v3.12"))
+	require.Equal(t, "", versionQuery("latest"))
+	require.Equal(t, "", versionQuery("*"))
+	require.Equal(t, "lts", versionQuery(" lts "))
+	require.Equal(t, "lts/iron", versionQuery("lts/iron"))
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -14,12 +15,18 @@ type Command interface {
 
 type ExecOptions struct {
 	CustomName string
+	Mounts     []Mount
+	Network    string
+	Workdir    string
 }
 
 // ExecCommand represents a shell command to be executed during the build
 type ExecCommand struct {
-	Cmd        string `json:"cmd" jsonschema:"description=The shell command to execute (e.g. 'go build' or 'npm install')"`
-	CustomName string `json:"customName,omitempty" jsonschema:"description=Optional custom name to display for this command in build output"`
+	Cmd        string  `json:"cmd" jsonschema:"description=The shell command to execute (e.g. 'go build' or 'npm install')"`
+	CustomName string  `json:"customName,omitempty" jsonschema:"description=Optional custom name to display for this command in build output"`
+	Mounts     []Mount `json:"mounts,omitempty" jsonschema:"description=Bind or tmpfs mounts that are only available while this command runs"`
+	Network    string  `json:"network,omitempty" jsonschema:"enum=default,enum=none,description=The network mode for this command. Defaults to default"`
+	Workdir    string  `json:"workdir,omitempty" jsonschema:"description=The directory to run this command in. Relative paths are resolved from /app"`
 }
 
 // PathCommand represents adding a directory to the global PATH environment variable
@@ -29,9 +36,23 @@ type PathCommand struct {
 
 // CopyCommand represents copying files or directories during the build
 type CopyCommand struct {
-	Image string `json:"image,omitempty" jsonschema:"description=Optional source image to copy from. This can be any public image URL"`
-	Src   string `json:"src" jsonschema:"description=Source path to copy from. Can be a file or directory"`
-	Dest  string `json:"dest" jsonschema:"description=Destination path to copy to. Will be created if it doesn't exist"`
+	Image          string   `json:"image,omitempty" jsonschema:"description=Optional source image to copy from. This can be any public image URL"`
+	Step           string   `json:"step,omitempty" jsonschema:"description=Optional step to copy from. Relative source paths are resolved from /app in the step output"`
+	Src            string   `json:"src" jsonschema:"description=Source path to copy from. Can be a file or directory"`
+	Dest           string   `json:"dest" jsonschema:"description=Destination path to copy to. Will be created if it doesn't exist"`
+	Chown          string   `json:"chown,omitempty" jsonschema:"description=Optional owner of the copied files as user or user:group (e.g. 1000:1000)"`
+	Chmod          string   `json:"chmod,omitempty" jsonschema:"description=Optional permissions of the copied files as an octal mode (e.g. 0755)"`
+	Exclude        []string `json:"exclude,omitempty" jsonschema:"description=Files or directories to exclude from the copy"`
+	FollowSymlinks *bool    `json:"followSymlinks,omitempty" jsonschema:"description=Whether to follow symlinks in the source path. Defaults to true"`
+}
+
+type CopyOptions struct {
+	Image          string
+	Step           string
+	Chown          string
+	Chmod          string
+	Exclude        []string
+	FollowSymlinks *bool
 }
 
 type FileOptions struct {
@@ -56,6 +77,9 @@ func NewExecCommand(cmd string, options ...ExecOptions) Command {
 	exec := ExecCommand{Cmd: cmd}
 	if len(options) > 0 {
 		exec.CustomName = options[0].CustomName
+		exec.Mounts = options[0].Mounts
+		exec.Network = options[0].Network
+		exec.Workdir = options[0].Workdir
 	}
 	return exec
 }
@@ -90,6 +114,24 @@ func NewCopyCommand(src string, dst ...string) Command {
 	return copyCmd
 }
 
+func NewCopyCommandWithOptions(src, dst string, options CopyOptions) Command {
+	return CopyCommand{
+		Image:          options.Image,
+		Step:           options.Step,
+		Src:            src,
+		Dest:           dst,
+		Chown:          options.Chown,
+		Chmod:          options.Chmod,
+		Exclude:        options.Exclude,
+		FollowSymlinks: options.FollowSymlinks,
+	}
+}
+
+// ShouldFollowSymlinks returns whether symlinks in the source path are followed, which is the default
+func (c CopyCommand) ShouldFollowSymlinks() bool {
+	return c.FollowSymlinks == nil || *c.FollowSymlinks
+}
+
 func NewFileCommand(path, name string, options ...FileOptions) Command {
 	fileCmd := FileCommand{Path: path, Name: name}
 	if len(options) > 0 {
@@ -152,11 +194,22 @@ func UnmarshalJsonCommand(data []byte) (Command, error) {
 }
 
 func UnmarshalStringCommand(data []byte) (Command, error) {
-	str := string(data)
+	// Commands in a plan or config are JSON strings. Raw commands can still be wrapped in quotes
+	var str string
+	trim := func(cmd string) string { return cmd }
+	if err := json.Unmarshal(data, &str); err != nil {
+		str = string(data)
+		trim = func(cmd string) string { return strings.Trim(cmd, "\"") }
+	}
+
+	// COPY --from=step src dest. Step names can contain a colon so this is checked before splitting the prefix
+	if payload, ok := strings.CutPrefix(str, "COPY "); ok {
+		return parseCopyPayload(payload)
+	}
 
 	// If no prefix, treat as exec command
 	if !strings.Contains(str, ":") {
-		cmdToRun := strings.Trim(str, "\"")
+		cmdToRun := trim(str)
 		return NewExecShellCommand(cmdToRun, ExecOptions{CustomName: cmdToRun}), nil
 	}
 
@@ -182,11 +235,7 @@ func UnmarshalStringCommand(data []byte) (Command, error) {
 	case "PATH":
 		return NewPathCommand(payload), nil
 	case "COPY":
-		copyParts := strings.Fields(payload)
-		if len(copyParts) != 2 {
-			return nil, fmt.Errorf("invalid COPY format: %s", payload)
-		}
-		return NewCopyCommand(copyParts[0], copyParts[1]), nil
+		return parseCopyPayload(payload)
 	case "FILE":
 		fileParts := strings.Fields(payload)
 		if len(fileParts) != 2 {
@@ -196,13 +245,53 @@ func UnmarshalStringCommand(data []byte) (Command, error) {
 	}
 
 	// fallback to exec command type
-	cmdToRun := strings.Trim(str, "\"")
+	cmdToRun := trim(str)
 	if customName == "" {
 		customName = cmdToRun
 	}
 	return NewExecShellCommand(cmdToRun, ExecOptions{CustomName: customName}), nil
 }
 
+// parseCopyPayload parses `[--from=step] [--chown=user:group] [--chmod=mode] [--exclude=pattern]...
+// [--follow-symlinks[=bool]] src dest`
+func parseCopyPayload(payload string) (Command, error) {
+	options := CopyOptions{}
+	paths := []string{}
+
+	for _, field := range strings.Fields(payload) {
+		switch {
+		case strings.HasPrefix(field, "--from="):
+			options.Step = strings.TrimPrefix(field, "--from=")
+		case strings.HasPrefix(field, "--chown="):
+			options.Chown = strings.TrimPrefix(field, "--chown=")
+		case strings.HasPrefix(field, "--chmod="):
+			options.Chmod = strings.TrimPrefix(field, "--chmod=")
+		case strings.HasPrefix(field, "--exclude="):
+			options.Exclude = append(options.Exclude, strings.TrimPrefix(field, "--exclude="))
+		case field == "--follow-symlinks" || strings.HasPrefix(field, "--follow-symlinks="):
+			follow := true
+			if value, ok := strings.CutPrefix(field, "--follow-symlinks="); ok {
+				parsed, err := strconv.ParseBool(value)
+				if err != nil {
+					return nil, fmt.Errorf("invalid COPY flag: %s", field)
+				}
+				follow = parsed
+			}
+			options.FollowSymlinks = &follow
+		case strings.HasPrefix(field, "--"):
+			return nil, fmt.Errorf("unknown COPY flag: %s", field)
+		default:
+			paths = append(paths, field)
+		}
+	}
+
+	if len(paths) != 2 {
+		return nil, fmt.Errorf("invalid COPY format: %s", payload)
+	}
+
+	return NewCopyCommandWithOptions(paths[0], paths[1], options), nil
+}
+
 func (e ExecCommand) IsSpread() bool {
 	return e.Cmd == ShellCommandString("...") || e.Cmd == "..."
 }
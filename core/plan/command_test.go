@@ -27,6 +27,19 @@ func TestCommandMarshalUnmarshal(t *testing.T) {
 			expectedJSON:    `{"cmd":"sh -c 'echo hello'","customName":"Say Hello"}`,
 			unmarshalString: "RUN#Say Hello:echo hello",
 		},
+		{
+			name: "exec command with mounts, network, and workdir",
+			command: NewExecShellCommand("go test ./...", ExecOptions{
+				CustomName: "test",
+				Mounts: []Mount{
+					NewStepBindMount("build", "testdata", "/app/testdata"),
+					NewTmpfsMount("/tmp"),
+				},
+				Network: NetworkModeNone,
+				Workdir: "server",
+			}),
+			expectedJSON: `{"cmd":"sh -c 'go test ./...'","customName":"test","mounts":[{"type":"bind","target":"/app/testdata","step":"build","source":"testdata"},{"type":"tmpfs","target":"/tmp"}],"network":"none","workdir":"server"}`,
+		},
 
 		// Path
 		{
@@ -43,6 +56,36 @@ func TestCommandMarshalUnmarshal(t *testing.T) {
 			expectedJSON:    `{"src":"src.txt","dest":"dst.txt"}`,
 			unmarshalString: "COPY:src.txt dst.txt",
 		},
+		{
+			name:            "copy command from step",
+			command:         NewCopyCommandWithOptions("dist", "/app/dist", CopyOptions{Step: "build"}),
+			expectedJSON:    `{"step":"build","src":"dist","dest":"/app/dist"}`,
+			unmarshalString: "COPY --from=build dist /app/dist",
+		},
+		{
+			name:            "copy command from step with a colon in its name",
+			command:         NewCopyCommandWithOptions("/mise", "/mise", CopyOptions{Step: "packages:mise"}),
+			expectedJSON:    `{"step":"packages:mise","src":"/mise","dest":"/mise"}`,
+			unmarshalString: "COPY:--from=packages:mise /mise /mise",
+		},
+		{
+			name:            "copy command with ownership and permissions",
+			command:         NewCopyCommandWithOptions("bin", "/usr/local/bin", CopyOptions{Step: "build", Chown: "1000:1000", Chmod: "0755"}),
+			expectedJSON:    `{"step":"build","src":"bin","dest":"/usr/local/bin","chown":"1000:1000","chmod":"0755"}`,
+			unmarshalString: "COPY --from=build --chown=1000:1000 --chmod=0755 bin /usr/local/bin",
+		},
+		{
+			name:            "copy command with exclude and symlinks",
+			command:         CopyCommand{Src: ".", Dest: ".", Exclude: []string{"node_modules"}, FollowSymlinks: new(bool)},
+			expectedJSON:    `{"src":".","dest":".","exclude":["node_modules"],"followSymlinks":false}`,
+			unmarshalString: "COPY --exclude=node_modules --follow-symlinks=false . .",
+		},
+		{
+			name:            "copy command with multiple excludes",
+			command:         NewCopyCommandWithOptions("dist", "/app/dist", CopyOptions{Step: "build", Exclude: []string{"*.map", "tmp"}}),
+			expectedJSON:    `{"step":"build","src":"dist","dest":"/app/dist","exclude":["*.map","tmp"]}`,
+			unmarshalString: "COPY --from=build --exclude=*.map --exclude=tmp dist /app/dist",
+		},
 
 		// File
 		{
@@ -89,3 +132,40 @@ func TestCommandMarshalUnmarshal(t *testing.T) {
 		})
 	}
 }
+
+func TestUnmarshalInvalidCopyCommand(t *testing.T) {
+	_, err := UnmarshalStringCommand([]byte("COPY --from=build dist"))
+	require.ErrorContains(t, err, "invalid COPY format")
+
+	_, err = UnmarshalStringCommand([]byte("COPY --link src dest"))
+	require.ErrorContains(t, err, "unknown COPY flag")
+
+	_, err = UnmarshalStringCommand([]byte("COPY --follow-symlinks=maybe src dest"))
+	require.ErrorContains(t, err, "invalid COPY flag")
+}
+
+func TestUnmarshalCopyFollowSymlinks(t *testing.T) {
+	cmd, err := UnmarshalStringCommand([]byte("COPY --follow-symlinks src dest"))
+	require.NoError(t, err)
+	require.NotNil(t, cmd.(CopyCommand).FollowSymlinks)
+	require.True(t, cmd.(CopyCommand).ShouldFollowSymlinks())
+}
+
+func TestUnmarshalQuotedStringCommand(t *testing.T) {
+	cmd, err := UnmarshalCommand([]byte(`"COPY --from=build dist /app/dist"`))
+	require.NoError(t, err)
+	require.Equal(t, NewCopyCommandWithOptions("dist", "/app/dist", CopyOptions{Step: "build"}), cmd)
+
+	cmd, err = UnmarshalCommand([]byte(`"PATH:/app/bin"`))
+	require.NoError(t, err)
+	require.Equal(t, NewPathCommand("/app/bin"), cmd)
+
+	// Escaped quotes of a JSON string are part of the command
+	cmd, err = UnmarshalCommand([]byte(`"echo \"hi\""`))
+	require.NoError(t, err)
+	require.Equal(t, NewExecShellCommand(`echo "hi"`, ExecOptions{CustomName: `echo "hi"`}), cmd)
+
+	cmd, err = UnmarshalCommand([]byte(`"RUN:echo \"hi\""`))
+	require.NoError(t, err)
+	require.Equal(t, NewExecShellCommand(`echo "hi"`, ExecOptions{}), cmd)
+}
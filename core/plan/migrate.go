@@ -0,0 +1,114 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// PlanVersion is the version of the plan format that this version of Railpack produces and understands
+const PlanVersion = 4
+
+// A Migration upgrades the raw JSON of a plan by a single version
+type Migration func(raw map[string]any) error
+
+// migrations[i] upgrades a plan from version i to version i+1.
+// Plans written before the version field was added have no version and are treated as version 0.
+var migrations = []Migration{
+	// 0 -> 1: The version field was added. The format is otherwise unchanged
+	func(raw map[string]any) error { return nil },
+
+	// 1 -> 2: Exec commands can have mounts, a network mode and a workdir. Older plans do not have them
+	func(raw map[string]any) error { return nil },
+
+	// 2 -> 3: Secrets are scoped to the steps that name them, and steps can have secret files
+	migrateScopedSecrets,
+
+	// 3 -> 4: Copy commands can copy from a step with chown, chmod, exclude and followSymlinks.
+	// Older copy commands are unchanged
+	func(raw map[string]any) error { return nil },
+}
+
+// migrateScopedSecrets keeps the secrets of older plans available to the steps that used them.
+// A step with any secrets used to get access to all secrets of the plan
+func migrateScopedSecrets(raw map[string]any) error {
+	steps, ok := raw["steps"].([]any)
+	if !ok {
+		return nil
+	}
+
+	for _, s := range steps {
+		step, ok := s.(map[string]any)
+		if !ok {
+			return fmt.Errorf("invalid step: %v", s)
+		}
+
+		if secrets, ok := step["secrets"].([]any); ok && len(secrets) > 0 {
+			step["secrets"] = []any{"*"}
+		}
+	}
+
+	return nil
+}
+
+// NewerPlanError is returned when a plan was written by a newer version of Railpack than this one
+type NewerPlanError struct {
+	Version int
+}
+
+func (e *NewerPlanError) Error() string {
+	return fmt.Sprintf("plan version %d is newer than the supported version %d. Upgrade Railpack to build this plan", e.Version, PlanVersion)
+}
+
+// MigratePlan upgrades the raw JSON of a plan to the current plan version
+func MigratePlan(data []byte) ([]byte, error) {
+	raw := map[string]any{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	version, err := planVersion(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if version > PlanVersion {
+		return nil, &NewerPlanError{Version: version}
+	}
+
+	if version == PlanVersion {
+		return data, nil
+	}
+
+	for v := version; v < PlanVersion; v++ {
+		if err := migrations[v](raw); err != nil {
+			return nil, fmt.Errorf("failed to migrate plan from version %d to %d: %w", v, v+1, err)
+		}
+	}
+
+	raw["version"] = PlanVersion
+	return json.Marshal(raw)
+}
+
+func planVersion(raw map[string]any) (int, error) {
+	value, ok := raw["version"]
+	if !ok || value == nil {
+		return 0, nil
+	}
+
+	version, ok := value.(float64)
+	if !ok || version < 0 || version != float64(int(version)) {
+		return 0, fmt.Errorf("invalid plan version: %v", value)
+	}
+
+	return int(version), nil
+}
+
+func (p *BuildPlan) UnmarshalJSON(data []byte) error {
+	migrated, err := MigratePlan(data)
+	if err != nil {
+		return err
+	}
+
+	type Alias BuildPlan
+	return json.Unmarshal(migrated, (*Alias)(p))
+}
@@ -0,0 +1,58 @@
+package plan
+
+import "fmt"
+
+const (
+	MountTypeBind  = "bind"
+	MountTypeTmpfs = "tmpfs"
+
+	NetworkModeDefault = "default"
+	NetworkModeNone    = "none"
+)
+
+// Mount represents a filesystem that is mounted into an exec command.
+// Mounted files are only available while the command runs and are not part of the resulting layer
+type Mount struct {
+	Type   string `json:"type" jsonschema:"enum=bind,enum=tmpfs,description=The type of mount. Bind mounts are read-only and tmpfs mounts are empty scratch space"`
+	Target string `json:"target" jsonschema:"description=The path in the container to mount to"`
+	Step   string `json:"step,omitempty" jsonschema:"description=The step whose output is bind mounted"`
+	Local  bool   `json:"local,omitempty" jsonschema:"description=Whether to bind mount the local context"`
+	Source string `json:"source,omitempty" jsonschema:"description=The path in the step output or local context to mount. Defaults to the root"`
+	Size   int64  `json:"size,omitempty" jsonschema:"description=The maximum size of a tmpfs mount in bytes"`
+}
+
+func NewStepBindMount(step, source, target string) Mount {
+	return Mount{Type: MountTypeBind, Step: step, Source: source, Target: target}
+}
+
+func NewLocalBindMount(source, target string) Mount {
+	return Mount{Type: MountTypeBind, Local: true, Source: source, Target: target}
+}
+
+func NewTmpfsMount(target string) Mount {
+	return Mount{Type: MountTypeTmpfs, Target: target}
+}
+
+func (m Mount) Validate() error {
+	if m.Target == "" {
+		return fmt.Errorf("mount target is required")
+	}
+
+	switch m.Type {
+	case MountTypeBind:
+		if (m.Step == "") == !m.Local {
+			return fmt.Errorf("bind mount at %s must have exactly one of step or local", m.Target)
+		}
+		if m.Size != 0 {
+			return fmt.Errorf("bind mount at %s cannot have a size", m.Target)
+		}
+	case MountTypeTmpfs:
+		if m.Step != "" || m.Local || m.Source != "" {
+			return fmt.Errorf("tmpfs mount at %s cannot have a source", m.Target)
+		}
+	default:
+		return fmt.Errorf("unknown mount type %q for %s. Must be one of: %s, %s", m.Type, m.Target, MountTypeBind, MountTypeTmpfs)
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMountValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		mount Mount
+		err   string
+	}{
+		{name: "step bind", mount: NewStepBindMount("build", "dist", "/app/dist")},
+		{name: "local bind", mount: NewLocalBindMount("fixtures", "/app/fixtures")},
+		{name: "tmpfs", mount: Mount{Type: MountTypeTmpfs, Target: "/tmp", Size: 1 << 20}},
+		{name: "missing target", mount: Mount{Type: MountTypeTmpfs}, err: "mount target is required"},
+		{name: "bind without source", mount: Mount{Type: MountTypeBind, Target: "/app/x"}, err: "exactly one of step or local"},
+		{name: "bind with step and local", mount: Mount{Type: MountTypeBind, Target: "/app/x", Step: "build", Local: true}, err: "exactly one of step or local"},
+		{name: "tmpfs with source", mount: Mount{Type: MountTypeTmpfs, Target: "/tmp", Step: "build"}, err: "cannot have a source"},
+		{name: "unknown type", mount: Mount{Type: "volume", Target: "/data"}, err: "unknown mount type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.mount.Validate()
+			if tt.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tt.err)
+			}
+		})
+	}
+}
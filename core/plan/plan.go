@@ -1,11 +1,16 @@
 package plan
 
+import "github.com/invopop/jsonschema"
+
 const (
 	RAILPACK_BUILDER_IMAGE = "ghcr.io/railwayapp/railpack-builder:latest"
 	RAILPACK_RUNTIME_IMAGE = "ghcr.io/railwayapp/railpack-runtime:latest"
 )
 
 type BuildPlan struct {
+	// The version of the plan format. Older plans are migrated when they are unmarshalled
+	Version int `json:"version"`
+
 	Steps   []Step            `json:"steps,omitempty"`
 	Caches  map[string]*Cache `json:"caches,omitempty"`
 	Secrets []string          `json:"secrets,omitempty"`
@@ -19,6 +24,9 @@ type Deploy struct {
 	// The command to run in the container
 	StartCmd string `json:"startCommand,omitempty"`
 
+	// The command to run once per release, before the new container starts receiving traffic
+	ReleaseCmd string `json:"releaseCommand,omitempty"`
+
 	// The variables available to this step. The key is the name of the variable that is referenced in a variable command
 	Variables map[string]string `json:"variables,omitempty"`
 
@@ -28,6 +36,7 @@ type Deploy struct {
 
 func NewBuildPlan() *BuildPlan {
 	return &BuildPlan{
+		Version: PlanVersion,
 		Steps:   []Step{},
 		Deploy:  Deploy{},
 		Caches:  make(map[string]*Cache),
@@ -38,3 +47,13 @@ func NewBuildPlan() *BuildPlan {
 func (p *BuildPlan) AddStep(step Step) {
 	p.Steps = append(p.Steps, step)
 }
+
+func GetJsonSchema() *jsonschema.Schema {
+	r := jsonschema.Reflector{
+		DoNotReference: true,
+		Anonymous:      true,
+	}
+
+	schema := r.Reflect(&BuildPlan{})
+	return schema
+}
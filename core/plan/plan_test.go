@@ -2,6 +2,7 @@ package plan
 
 import (
 	"encoding/json"
+	"fmt"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -81,3 +82,76 @@ func TestSerialization(t *testing.T) {
 		t.Errorf("plans mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestMigrationsCoverPlanVersion(t *testing.T) {
+	require.Len(t, migrations, PlanVersion)
+}
+
+func TestUnmarshalMigratesUnversionedPlan(t *testing.T) {
+	jsonPlan := `{
+		"steps": [{"name": "build", "commands": ["npm run build"]}],
+		"deploy": {"startCommand": "npm start"}
+	}`
+
+	var plan BuildPlan
+	require.NoError(t, json.Unmarshal([]byte(jsonPlan), &plan))
+
+	require.Equal(t, PlanVersion, plan.Version)
+	require.Equal(t, "build", plan.Steps[0].Name)
+	require.Equal(t, "npm start", plan.Deploy.StartCmd)
+}
+
+func TestUnmarshalMigratesScopedSecrets(t *testing.T) {
+	jsonPlan := `{
+		"version": 2,
+		"secrets": ["NPM_TOKEN", "DATABASE_URL"],
+		"steps": [
+			{"name": "install", "secrets": ["NPM_TOKEN"]},
+			{"name": "build", "secrets": []}
+		]
+	}`
+
+	var plan BuildPlan
+	require.NoError(t, json.Unmarshal([]byte(jsonPlan), &plan))
+
+	require.Equal(t, PlanVersion, plan.Version)
+	require.True(t, plan.Steps[0].UsesSecret("DATABASE_URL"))
+	require.False(t, plan.Steps[1].UsesSecret("DATABASE_URL"))
+
+	// Plans of the current version are not migrated
+	jsonPlan = fmt.Sprintf(`{"version": %d, "secrets": ["NPM_TOKEN", "DATABASE_URL"], "steps": [{"name": "install", "secrets": ["NPM_TOKEN"]}]}`, PlanVersion)
+	require.NoError(t, json.Unmarshal([]byte(jsonPlan), &plan))
+	require.False(t, plan.Steps[0].UsesSecret("DATABASE_URL"))
+}
+
+func TestUnmarshalNewerPlan(t *testing.T) {
+	jsonPlan := fmt.Sprintf(`{"version": %d, "steps": []}`, PlanVersion+1)
+
+	var plan BuildPlan
+	err := json.Unmarshal([]byte(jsonPlan), &plan)
+
+	var newerErr *NewerPlanError
+	require.ErrorAs(t, err, &newerErr)
+	require.Equal(t, PlanVersion+1, newerErr.Version)
+	require.Contains(t, err.Error(), "Upgrade Railpack")
+}
+
+func TestUnmarshalInvalidPlanVersion(t *testing.T) {
+	var plan BuildPlan
+	require.Error(t, json.Unmarshal([]byte(`{"version": "one"}`), &plan))
+}
+
+func TestStepUsesSecret(t *testing.T) {
+	step := NewStep("build")
+	require.True(t, step.UsesSecret("NPM_TOKEN"))
+
+	step.Secrets = []string{"DATABASE_URL"}
+	require.True(t, step.UsesSecret("DATABASE_URL"))
+	require.False(t, step.UsesSecret("NPM_TOKEN"))
+
+	step.Secrets = []string{}
+	require.False(t, step.UsesSecret("DATABASE_URL"))
+
+	step.SecretFiles = []SecretFile{{Secret: "NPMRC", Target: ".npmrc"}}
+	require.Equal(t, []string{"NPMRC"}, step.SecretFileNames())
+}
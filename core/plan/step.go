@@ -2,18 +2,28 @@ package plan
 
 import (
 	"encoding/json"
+	"os"
+	"slices"
 
 	"github.com/invopop/jsonschema"
 )
 
 type Step struct {
-	Name      string            `json:"name,omitempty" jsonschema:"description=The name of the step"`
-	Inputs    []Input           `json:"inputs,omitempty" jsonschema:"description=The inputs for this step"`
-	Commands  []Command         `json:"commands,omitempty" jsonschema:"description=The commands to run in this step"`
-	Secrets   []string          `json:"secrets,omitempty" jsonschema:"description=The secrets that this step uses"`
-	Assets    map[string]string `json:"assets,omitempty" jsonschema:"description=The assets available to this step. The key is the name of the asset that is referenced in a file command"`
-	Variables map[string]string `json:"variables,omitempty" jsonschema:"description=The variables available to this step. The key is the name of the variable that is referenced in a variable command"`
-	Caches    []string          `json:"caches,omitempty" jsonschema:"description=The caches available to all commands in this step. Each cache must refer to a cache at the top level of the plan"`
+	Name        string            `json:"name,omitempty" jsonschema:"description=The name of the step"`
+	Inputs      []Input           `json:"inputs,omitempty" jsonschema:"description=The inputs for this step"`
+	Commands    []Command         `json:"commands,omitempty" jsonschema:"description=The commands to run in this step"`
+	Secrets     []string          `json:"secrets,omitempty" jsonschema:"description=The secrets that this step uses"`
+	Assets      map[string]string `json:"assets,omitempty" jsonschema:"description=The assets available to this step. The key is the name of the asset that is referenced in a file command"`
+	Variables   map[string]string `json:"variables,omitempty" jsonschema:"description=The variables available to this step. The key is the name of the variable that is referenced in a variable command"`
+	Caches      []string          `json:"caches,omitempty" jsonschema:"description=The caches available to all commands in this step. Each cache must refer to a cache at the top level of the plan"`
+	SecretFiles []SecretFile      `json:"secretFiles,omitempty" jsonschema:"description=Secrets that are mounted as files instead of environment variables"`
+}
+
+// SecretFile represents a secret that is mounted into every exec command of a step as a file
+type SecretFile struct {
+	Secret string      `json:"secret" jsonschema:"description=The name of the secret. Must be one of the secrets at the top level of the plan"`
+	Target string      `json:"target" jsonschema:"description=The path to mount the secret at. Relative paths are resolved from /app"`
+	Mode   os.FileMode `json:"mode,omitempty" jsonschema:"description=Optional Unix file permissions mode. Defaults to 0400"`
 }
 
 func NewStep(name string) *Step {
@@ -32,6 +42,21 @@ func (s *Step) AddCommands(commands []Command) {
 	s.Commands = append(s.Commands, commands...)
 }
 
+// UsesSecret checks if the commands of this step have access to the secret as an environment variable.
+// A "*" in the step secrets gives access to all secrets
+func (s *Step) UsesSecret(secret string) bool {
+	return slices.Contains(s.Secrets, "*") || slices.Contains(s.Secrets, secret)
+}
+
+// SecretFileNames returns the names of the secrets that are mounted as files
+func (s *Step) SecretFileNames() []string {
+	names := []string{}
+	for _, secretFile := range s.SecretFiles {
+		names = append(names, secretFile.Secret)
+	}
+	return names
+}
+
 func (s *Step) UnmarshalJSON(data []byte) error {
 	type Alias Step
 	aux := &struct {
@@ -94,8 +94,9 @@ var (
 )
 
 type PrintOptions struct {
-	Metadata bool
-	Version  string
+	Metadata  bool
+	Providers bool
+	Version   string
 }
 
 func PrettyPrintBuildResult(buildResult *BuildResult, options ...PrintOptions) {
@@ -115,6 +116,7 @@ func FormatBuildResult(br *BuildResult, options ...PrintOptions) string {
 	formatPackages(&output, br.ResolvedPackages)
 	formatSteps(&output, br)
 	formatDeploy(&output, br)
+	formatProviders(&output, br.DetectedProviders, opts.Providers)
 	formatMetadata(&output, br.Metadata, opts.Metadata)
 
 	output.WriteString("\n\n")
@@ -233,10 +235,43 @@ func formatDeploy(output *strings.Builder, br *BuildResult) {
 	if br.Plan != nil && br.Plan.Deploy.StartCmd != "" {
 		output.WriteString(sectionHeaderStyle.MarginTop(1).Render("Deploy"))
 		output.WriteString("\n")
+		if br.Plan.Deploy.ReleaseCmd != "" {
+			output.WriteString(fmt.Sprintf("%s %s", commandPrefixStyle.Render("release $"), commandStyle.Render(br.Plan.Deploy.ReleaseCmd)))
+			output.WriteString("\n")
+		}
 		output.WriteString(fmt.Sprintf("%s %s", commandPrefixStyle.Render("$"), commandStyle.Render(br.Plan.Deploy.StartCmd)))
 	}
 }
 
+func formatProviders(output *strings.Builder, detectedProviders []*DetectedProvider, showProviders bool) {
+	if !showProviders || len(detectedProviders) == 0 {
+		return
+	}
+
+	output.WriteString(sectionHeaderStyle.MarginTop(2).Render("Providers"))
+	output.WriteString("\n")
+
+	nameWidth := 1
+	for _, detected := range detectedProviders {
+		nameWidth = max(nameWidth, len(detected.Name))
+	}
+
+	localPackageNameStyle := packageNameStyle.Width(nameWidth)
+	separator := separatorStyle.Render("│")
+
+	for _, detected := range detectedProviders {
+		marker := " "
+		if detected.Selected {
+			marker = "✔"
+		}
+
+		name := localPackageNameStyle.Render(detected.Name)
+		score := versionStyle.Render(fmt.Sprintf("%3d", detected.Score))
+		output.WriteString(fmt.Sprintf("%s%s%s%s%s %s", name, separator, score, separator, marker, strings.Join(detected.Reasons, ", ")))
+		output.WriteString("\n")
+	}
+}
+
 func formatMetadata(output *strings.Builder, metadata map[string]string, showMetadata bool) {
 	if !showMetadata || metadata == nil || len(metadata) == 0 {
 		return
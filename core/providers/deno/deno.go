@@ -25,8 +25,22 @@ func (p *DenoProvider) Name() string {
 }
 
 func (p *DenoProvider) Detect(ctx *generate.GenerateContext) (bool, error) {
-	hasDenoJson := ctx.App.HasMatch("deno.json") || ctx.App.HasMatch("deno.jsonc")
-	return hasDenoJson, nil
+	detection, err := p.DetectScore(ctx)
+	return detection.Matched(), err
+}
+
+func (p *DenoProvider) DetectScore(ctx *generate.GenerateContext) (*generate.Detection, error) {
+	detection := &generate.Detection{}
+	if !detection.AddMatch(ctx, "deno.json", generate.DetectionScoreManifest) {
+		detection.AddMatch(ctx, "deno.jsonc", generate.DetectionScoreManifest)
+	}
+
+	return detection, nil
+}
+
+// ComposesWith returns the providers whose apps are built as part of the Deno app
+func (p *DenoProvider) ComposesWith() []string {
+	return []string{"node"}
 }
 
 func (p *DenoProvider) Initialize(ctx *generate.GenerateContext) error {
@@ -39,14 +53,13 @@ func (p *DenoProvider) Plan(ctx *generate.GenerateContext) error {
 	p.InstallMisePackages(ctx, miseStep)
 
 	build := ctx.NewCommandStep("build")
+	build.UseBuildArgs("*")
 	build.AddInput(plan.NewStepInput(miseStep.Name()))
 	p.Build(ctx, build)
 
 	ctx.Deploy.Inputs = []plan.Input{
 		ctx.DefaultRuntimeInput(),
-		plan.NewStepInput(miseStep.Name(), plan.InputOptions{
-			Include: miseStep.GetOutputPaths(),
-		}),
+		ctx.MiseRuntimeInput(),
 		plan.NewStepInput(build.Name(), plan.InputOptions{
 			Include: []string{".", DENO_DIR},
 		}),
@@ -83,11 +96,7 @@ func (p *DenoProvider) Build(ctx *generate.GenerateContext, build *generate.Comm
 }
 
 func (p *DenoProvider) InstallMisePackages(ctx *generate.GenerateContext, miseStep *generate.MiseStepBuilder) {
-	deno := miseStep.Default("deno", DEFAULT_DENO_VERSION)
-
-	if envVersion, varName := ctx.Env.GetConfigVariable("DENO_VERSION"); envVersion != "" {
-		miseStep.Version(deno, envVersion, varName)
-	}
+	miseStep.Default("deno", DEFAULT_DENO_VERSION)
 }
 
 func (p *DenoProvider) findMainFile(ctx *generate.GenerateContext) string {
@@ -23,7 +23,16 @@ func (p *GoProvider) Name() string {
 }
 
 func (p *GoProvider) Detect(ctx *generate.GenerateContext) (bool, error) {
-	return p.isGoMod(ctx) || ctx.App.HasMatch("main.go"), nil
+	detection, err := p.DetectScore(ctx)
+	return detection.Matched(), err
+}
+
+func (p *GoProvider) DetectScore(ctx *generate.GenerateContext) (*generate.Detection, error) {
+	detection := &generate.Detection{}
+	detection.AddMatch(ctx, "go.mod", generate.DetectionScoreManifest)
+	detection.AddMatch(ctx, "main.go", generate.DetectionScoreEntrypoint)
+
+	return detection, nil
 }
 
 func (p *GoProvider) Initialize(ctx *generate.GenerateContext) error {
@@ -35,10 +44,12 @@ func (p *GoProvider) Plan(ctx *generate.GenerateContext) error {
 	p.InstallGoPackages(ctx, builder)
 
 	install := ctx.NewCommandStep("install")
+	install.UseBuildArgs("*")
 	install.AddInput(plan.NewStepInput(builder.Name()))
 	p.InstallGoDeps(ctx, install)
 
 	build := ctx.NewCommandStep("build")
+	build.UseBuildArgs("*")
 	build.AddInput(plan.NewStepInput(install.Name()))
 	p.Build(ctx, build)
 
@@ -124,25 +135,8 @@ func (p *GoProvider) InstallGoDeps(ctx *generate.GenerateContext, install *gener
 }
 
 func (p *GoProvider) InstallGoPackages(ctx *generate.GenerateContext, miseStep *generate.MiseStepBuilder) {
-	goPkg := miseStep.Default("go", DEFAULT_GO_VERSION)
-
-	if goModContents, err := ctx.App.ReadFile("go.mod"); err == nil {
-		// Split content into lines and look for "go X.XX" line
-		lines := strings.Split(string(goModContents), "\n")
-		for _, line := range lines {
-			if strings.HasPrefix(strings.TrimSpace(line), "go ") {
-				// Extract version number
-				if goVersion := strings.TrimSpace(strings.TrimPrefix(line, "go")); goVersion != "" {
-					miseStep.Version(goPkg, goVersion, "go.mod")
-					break
-				}
-			}
-		}
-	}
-
-	if envVersion, varName := ctx.Env.GetConfigVariable("GO_VERSION"); envVersion != "" {
-		miseStep.Version(goPkg, envVersion, varName)
-	}
+	// The version the app requests is found by the version hints of the resolver
+	miseStep.Default("go", DEFAULT_GO_VERSION)
 }
 
 func (p *GoProvider) GetBuilder(ctx *generate.GenerateContext) *generate.MiseStepBuilder {
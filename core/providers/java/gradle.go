@@ -18,11 +18,7 @@ func (p *JavaProvider) usesGradle(ctx *generate.GenerateContext) bool {
 
 func (p *JavaProvider) setGradleVersion(ctx *generate.GenerateContext) {
 	miseStep := ctx.GetMiseStepBuilder()
-	gradle := miseStep.Default("gradle", DEFAULT_GRADLE_VERSION)
-
-	if envVersion, envName := ctx.Env.GetConfigVariable("GRADLE_VERSION"); envVersion != "" {
-		miseStep.Version(gradle, envVersion, envName)
-	}
+	gradle := miseStep.DefaultBuildOnly("gradle", DEFAULT_GRADLE_VERSION)
 
 	if !ctx.App.HasMatch("gradle/wrapper/gradle-wrapper.properties") {
 		return
@@ -14,7 +14,16 @@ func (p *JavaProvider) Name() string {
 }
 
 func (p *JavaProvider) Detect(ctx *generate.GenerateContext) (bool, error) {
-	return ctx.App.HasMatch("pom.{xml,atom,clj,groovy,rb,scala,yaml,yml}") || ctx.App.HasMatch("gradlew"), nil
+	detection, err := p.DetectScore(ctx)
+	return detection.Matched(), err
+}
+
+func (p *JavaProvider) DetectScore(ctx *generate.GenerateContext) (*generate.Detection, error) {
+	detection := &generate.Detection{}
+	detection.AddMatch(ctx, "pom.{xml,atom,clj,groovy,rb,scala,yaml,yml}", generate.DetectionScoreManifest)
+	detection.AddMatch(ctx, "gradlew", generate.DetectionScoreManifest)
+
+	return detection, nil
 }
 
 func (p *JavaProvider) Initialize(ctx *generate.GenerateContext) error {
@@ -27,6 +36,7 @@ func (p *JavaProvider) StartCommandHelp() string {
 
 func (p *JavaProvider) Plan(ctx *generate.GenerateContext) error {
 	build := ctx.NewCommandStep("build")
+	build.UseBuildArgs("*")
 	build.AddCommand(plan.NewCopyCommand("."))
 	build.Inputs = []plan.Input{plan.NewStepInput(ctx.GetMiseStepBuilder().Name())}
 
@@ -45,7 +55,7 @@ func (p *JavaProvider) Plan(ctx *generate.GenerateContext) error {
 	} else {
 		ctx.Logger.LogInfo("Using Maven")
 
-		ctx.GetMiseStepBuilder().Default("maven", "latest")
+		ctx.GetMiseStepBuilder().DefaultBuildOnly("maven", "latest")
 		p.setJDKVersion(ctx, ctx.GetMiseStepBuilder())
 
 		if ctx.App.HasMatch("mvnw") && !ctx.App.IsFileExecutable("mvnw") {
@@ -56,9 +66,6 @@ func (p *JavaProvider) Plan(ctx *generate.GenerateContext) error {
 		build.AddCache(p.mavenCache(ctx))
 	}
 
-	runtimeMiseStep := ctx.NewMiseStepBuilder("packages:mise:runtime")
-	p.setJDKVersion(ctx, runtimeMiseStep)
-
 	outPath := "target/."
 	if ctx.App.HasMatch("**/build/libs/*.jar") || p.usesGradle(ctx) {
 		outPath = "."
@@ -66,9 +73,7 @@ func (p *JavaProvider) Plan(ctx *generate.GenerateContext) error {
 
 	ctx.Deploy.Inputs = []plan.Input{
 		ctx.DefaultRuntimeInput(),
-		plan.NewStepInput(runtimeMiseStep.Name(), plan.InputOptions{
-			Include: runtimeMiseStep.GetOutputPaths(),
-		}),
+		ctx.MiseRuntimeInput(),
 		plan.NewStepInput(build.Name(), plan.InputOptions{
 			Include: []string{outPath},
 		}),
@@ -10,9 +10,6 @@ const DEFAULT_JDK_VERSION = "21"
 
 func (p *JavaProvider) setJDKVersion(ctx *generate.GenerateContext, miseStep *generate.MiseStepBuilder) {
 	jdk := miseStep.Default("java", DEFAULT_JDK_VERSION)
-	if jdkVersion, envName := ctx.Env.GetConfigVariable("JDK_VERSION"); jdkVersion != "" {
-		miseStep.Version(jdk, jdkVersion, envName)
-	}
 
 	if p.usesGradle(ctx) {
 		gradleVersion, err := strconv.Atoi(miseStep.Resolver.Get("gradle").Version)
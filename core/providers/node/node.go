@@ -49,7 +49,15 @@ func (p *NodeProvider) Initialize(ctx *generate.GenerateContext) error {
 }
 
 func (p *NodeProvider) Detect(ctx *generate.GenerateContext) (bool, error) {
-	return ctx.App.HasMatch("package.json"), nil
+	detection, err := p.DetectScore(ctx)
+	return detection.Matched(), err
+}
+
+func (p *NodeProvider) DetectScore(ctx *generate.GenerateContext) (*generate.Detection, error) {
+	detection := &generate.Detection{}
+	detection.AddMatch(ctx, "package.json", generate.DetectionScoreManifest)
+
+	return detection, nil
 }
 
 func (p *NodeProvider) Plan(ctx *generate.GenerateContext) error {
@@ -72,6 +80,7 @@ func (p *NodeProvider) Plan(ctx *generate.GenerateContext) error {
 
 	// Install
 	install := ctx.NewCommandStep("install")
+	install.UseBuildArgs("*")
 	install.AddInput(plan.NewStepInput(miseStep.Name()))
 	p.InstallNodeDeps(ctx, install)
 
@@ -85,11 +94,13 @@ func (p *NodeProvider) Plan(ctx *generate.GenerateContext) error {
 
 	// Build
 	build := ctx.NewCommandStep("build")
+	build.UseBuildArgs("*")
 	build.AddInput(plan.NewStepInput(install.Name()))
 	p.Build(ctx, build)
 
 	// Deploy
 	ctx.Deploy.StartCmd = p.GetStartCommand(ctx)
+	ctx.Deploy.ReleaseCmd = p.GetReleaseCommand(ctx)
 	maps.Copy(ctx.Deploy.Variables, p.GetNodeEnvVars(ctx))
 
 	// Custom deploy for SPA's
@@ -131,9 +142,7 @@ func (p *NodeProvider) Plan(ctx *generate.GenerateContext) error {
 
 	ctx.Deploy.Inputs = []plan.Input{
 		ctx.DefaultRuntimeInputWithPackages(runtimeAptPackages),
-		plan.NewStepInput(miseStep.Name(), plan.InputOptions{
-			Include: miseStep.GetOutputPaths(),
-		}),
+		ctx.MiseRuntimeInput(),
 		nodeModulesInput,
 		buildInput,
 	}
@@ -141,6 +150,43 @@ func (p *NodeProvider) Plan(ctx *generate.GenerateContext) error {
 	return nil
 }
 
+// PlanAuxiliary builds the app as part of an app that is deployed by another provider.
+// Only the build output is used, so apps without a build script are skipped
+func (p *NodeProvider) PlanAuxiliary(ctx *generate.GenerateContext) ([]plan.Input, error) {
+	if p.packageJson == nil {
+		return nil, fmt.Errorf("package.json not found")
+	}
+
+	if _, ok := p.packageJson.Scripts["build"]; !ok {
+		ctx.Logger.LogInfo("Skipping Node since package.json has no build script")
+		return nil, nil
+	}
+
+	p.SetNodeMetadata(ctx)
+
+	ctx.Logger.LogInfo("Building Node with %s", p.packageManager)
+
+	miseStep := ctx.GetMiseStepBuilder()
+	p.InstallMisePackages(ctx, miseStep)
+
+	install := ctx.NewCommandStep("install")
+	install.UseBuildArgs("*")
+	install.AddInput(plan.NewStepInput(miseStep.Name()))
+	p.InstallNodeDeps(ctx, install)
+
+	build := ctx.NewCommandStep("build")
+	build.UseBuildArgs("*")
+	build.AddInput(plan.NewStepInput(install.Name()))
+	p.Build(ctx, build)
+
+	return []plan.Input{
+		plan.NewStepInput(build.Name(), plan.InputOptions{
+			Include: []string{"."},
+			Exclude: []string{"node_modules", ".yarn"},
+		}),
+	}, nil
+}
+
 func (p *NodeProvider) StartCommandHelp() string {
 	return "To configure your start command, Railpack will check:\n\n" +
 		"1. A \"start\" script in your package.json:\n" +
@@ -169,6 +215,35 @@ func (p *NodeProvider) GetStartCommand(ctx *generate.GenerateContext) string {
 	return ""
 }
 
+// usesPackageManagerAtRuntime checks if the start or release command runs the package manager
+func (p *NodeProvider) usesPackageManagerAtRuntime(ctx *generate.GenerateContext) bool {
+	if p.getScripts(p.packageJson, "start") != "" || p.usesPrisma() {
+		return true
+	}
+
+	startCmd, _ := ctx.Env.GetConfigVariable("START_CMD")
+	if ctx.Config.Deploy != nil {
+		startCmd += " " + ctx.Config.Deploy.StartCmd + " " + ctx.Config.Deploy.ReleaseCmd
+	}
+
+	name := p.packageManager.Name()
+	for _, field := range strings.Fields(startCmd) {
+		if field == name || field == name+"x" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *NodeProvider) GetReleaseCommand(ctx *generate.GenerateContext) string {
+	if p.usesPrisma() {
+		return p.packageManager.ExecCmd("prisma migrate deploy")
+	}
+
+	return ""
+}
+
 func (p *NodeProvider) Build(ctx *generate.GenerateContext, build *generate.CommandStepBuilder) {
 	build.AddCommand(plan.NewCopyCommand("."))
 
@@ -250,32 +325,13 @@ func (p *NodeProvider) InstallMisePackages(ctx *generate.GenerateContext, miseSt
 
 	// Node
 	if requiresNode {
-		node := miseStep.Default("node", DEFAULT_NODE_VERSION)
-
-		if envVersion, varName := ctx.Env.GetConfigVariable("NODE_VERSION"); envVersion != "" {
-			miseStep.Version(node, envVersion, varName)
-		}
-
-		if p.packageJson.Engines != nil && p.packageJson.Engines["node"] != "" {
-			miseStep.Version(node, p.packageJson.Engines["node"], "package.json > engines > node")
-		}
-
-		if nvmrc, err := ctx.App.ReadFile(".nvmrc"); err == nil {
-			if len(nvmrc) > 0 && nvmrc[0] == 'v' {
-				nvmrc = nvmrc[1:]
-			}
-
-			miseStep.Version(node, string(nvmrc), ".nvmrc")
-		}
+		// The version the app requests is found by the version hints of the resolver
+		miseStep.Default("node", DEFAULT_NODE_VERSION)
 	}
 
 	// Bun
 	if p.requiresBun(ctx) {
-		bun := miseStep.Default("bun", DEFAULT_BUN_VERSION)
-
-		if envVersion, varName := ctx.Env.GetConfigVariable("BUN_VERSION"); envVersion != "" {
-			miseStep.Version(bun, envVersion, varName)
-		}
+		miseStep.Default("bun", DEFAULT_BUN_VERSION)
 
 		// If we don't need node in the final image, we still want to include it for the install steps
 		// since many packages need node-gyp to install native modules
@@ -285,7 +341,7 @@ func (p *NodeProvider) InstallMisePackages(ctx *generate.GenerateContext, miseSt
 		}
 	}
 
-	p.packageManager.GetPackageManagerPackages(ctx, p.packageJson, miseStep)
+	p.packageManager.GetPackageManagerPackages(ctx, p.packageJson, miseStep, p.usesPackageManagerAtRuntime(ctx))
 
 	if p.usesCorepack() {
 		miseStep.Variables["MISE_NODE_COREPACK"] = "true"
@@ -321,6 +377,10 @@ func (p *NodeProvider) usesCorepack() bool {
 	return p.packageJson.PackageManager != nil && p.packageManager != PackageManagerBun
 }
 
+func (p *NodeProvider) usesPrisma() bool {
+	return p.workspace.HasDependency("prisma")
+}
+
 func (p *NodeProvider) usesPuppeteer() bool {
 	return p.workspace.HasDependency("puppeteer")
 }
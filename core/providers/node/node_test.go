@@ -156,3 +156,34 @@ func TestGetNextApps(t *testing.T) {
 		})
 	}
 }
+
+func TestUsesPackageManagerAtRuntime(t *testing.T) {
+	tests := []struct {
+		name       string
+		startCmd   string
+		envVar     string
+		releaseCmd string
+		want       bool
+	}{
+		{name: "no start command", want: false},
+		{name: "config start command", startCmd: "pnpm preview", want: true},
+		{name: "env start command", envVar: "pnpm run serve", want: true},
+		{name: "config release command", releaseCmd: "pnpm exec drizzle-kit migrate", want: true},
+		{name: "other start command", startCmd: "node build/index.js", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := testingUtils.CreateGenerateContext(t, "../../../examples/node-svelte-kit")
+			ctx.Config.Deploy.StartCmd = tt.startCmd
+			ctx.Config.Deploy.ReleaseCmd = tt.releaseCmd
+			if tt.envVar != "" {
+				ctx.Env.SetVariable("RAILPACK_START_CMD", tt.envVar)
+			}
+
+			provider := NodeProvider{}
+			require.NoError(t, provider.Initialize(ctx))
+			require.Equal(t, tt.want, provider.usesPackageManagerAtRuntime(ctx))
+		})
+	}
+}
@@ -35,6 +35,20 @@ func (p PackageManager) RunCmd(cmd string) string {
 	return fmt.Sprintf("%s run %s", p.Name(), cmd)
 }
 
+// ExecCmd returns the command to run a binary provided by an installed package
+func (p PackageManager) ExecCmd(cmd string) string {
+	switch p {
+	case PackageManagerPnpm:
+		return "pnpm exec " + cmd
+	case PackageManagerBun:
+		return "bunx " + cmd
+	case PackageManagerYarn1, PackageManagerYarn2:
+		return "yarn " + cmd
+	default:
+		return "npx " + cmd
+	}
+}
+
 func (p PackageManager) RunScriptCommand(cmd string) string {
 	if p == PackageManagerBun {
 		return "bun " + cmd
@@ -184,11 +198,17 @@ func (p PackageManager) SupportingInstallFiles(ctx *generate.GenerateContext) []
 	return allFiles
 }
 
-// GetPackageManagerPackages installs specific versions of package managers by analyzing the users code
-func (p PackageManager) GetPackageManagerPackages(ctx *generate.GenerateContext, packageJson *PackageJson, packages *generate.MiseStepBuilder) {
+// GetPackageManagerPackages installs specific versions of package managers by analyzing the users code.
+// The package manager is left out of the runtime image unless the start or release command uses it
+func (p PackageManager) GetPackageManagerPackages(ctx *generate.GenerateContext, packageJson *PackageJson, packages *generate.MiseStepBuilder, usedAtRuntime bool) {
+	addPackage := packages.DefaultBuildOnly
+	if usedAtRuntime {
+		addPackage = packages.Default
+	}
+
 	// Pnpm
 	if p == PackageManagerPnpm {
-		pnpm := packages.Default("pnpm", "latest")
+		pnpm := addPackage("pnpm", "latest")
 
 		lockfile, err := ctx.App.ReadFile("pnpm-lock.yaml")
 		if err == nil {
@@ -210,11 +230,11 @@ func (p PackageManager) GetPackageManagerPackages(ctx *generate.GenerateContext,
 	// Yarn
 	if p == PackageManagerYarn1 || p == PackageManagerYarn2 {
 		if p == PackageManagerYarn1 {
-			packages.Default("yarn", "1")
+			addPackage("yarn", "1")
 			packages.AddSupportingAptPackage("tar")
 			packages.AddSupportingAptPackage("gpg")
 		} else {
-			packages.Default("yarn", "2")
+			addPackage("yarn", "2")
 		}
 
 		name, version := p.parsePackageManagerField(packageJson)
@@ -224,14 +244,14 @@ func (p PackageManager) GetPackageManagerPackages(ctx *generate.GenerateContext,
 			// Only apply version if it matches the expected yarn version
 			if (majorVersion == "1" && p == PackageManagerYarn1) ||
 				(majorVersion != "1" && p == PackageManagerYarn2) {
-				packages.Version(packages.Default("yarn", majorVersion), version, "package.json > packageManager")
+				packages.Version(addPackage("yarn", majorVersion), version, "package.json > packageManager")
 			}
 		}
 	}
 
 	// Bun
 	if p == PackageManagerBun {
-		bun := packages.Default("bun", "latest")
+		bun := addPackage("bun", "latest")
 
 		name, version := p.parsePackageManagerField(packageJson)
 		if name == "bun" && version != "" {
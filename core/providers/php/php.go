@@ -11,7 +11,6 @@ import (
 	"github.com/unbindapp/railpack/core/generate"
 	"github.com/unbindapp/railpack/core/plan"
 	"github.com/unbindapp/railpack/core/providers/node"
-	"github.com/stretchr/objx"
 )
 
 const (
@@ -36,8 +35,21 @@ func (p *PhpProvider) Name() string {
 }
 
 func (p *PhpProvider) Detect(ctx *generate.GenerateContext) (bool, error) {
-	return ctx.App.HasMatch("index.php") ||
-		ctx.App.HasMatch("composer.json"), nil
+	detection, err := p.DetectScore(ctx)
+	return detection.Matched(), err
+}
+
+func (p *PhpProvider) DetectScore(ctx *generate.GenerateContext) (*generate.Detection, error) {
+	detection := &generate.Detection{}
+	detection.AddMatch(ctx, "composer.json", generate.DetectionScoreManifest)
+	detection.AddMatch(ctx, "index.php", generate.DetectionScoreEntrypoint)
+
+	return detection, nil
+}
+
+// ComposesWith returns the providers whose apps are built as part of the PHP app
+func (p *PhpProvider) ComposesWith() []string {
+	return []string{"node"}
 }
 
 func (p *PhpProvider) Initialize(ctx *generate.GenerateContext) error {
@@ -66,6 +78,7 @@ func (p *PhpProvider) Plan(ctx *generate.GenerateContext) error {
 	p.InstallExtensions(ctx, extensions)
 
 	composer := ctx.NewCommandStep("install:composer")
+	composer.UseBuildArgs("*")
 	composer.AddInput(plan.NewStepInput(extensions.Name()))
 	p.InstallCompose(ctx, composer)
 
@@ -88,6 +101,7 @@ func (p *PhpProvider) Plan(ctx *generate.GenerateContext) error {
 	} else {
 		// A manual build command will go here
 		build := ctx.NewCommandStep("build")
+		build.UseBuildArgs("*")
 		build.AddInput(plan.NewStepInput(composer.Name()))
 		build.AddCommand(plan.NewCopyCommand("."))
 		ctx.Deploy.Inputs = []plan.Input{
@@ -97,6 +111,10 @@ func (p *PhpProvider) Plan(ctx *generate.GenerateContext) error {
 
 	ctx.Deploy.StartCmd = "/start-container.sh"
 
+	if isLaravel && !ctx.Env.IsConfigVariableTruthy("SKIP_MIGRATIONS") {
+		ctx.Deploy.ReleaseCmd = "php artisan migrate --force"
+	}
+
 	return nil
 }
 
@@ -189,6 +207,7 @@ func (p *PhpProvider) DeployWithNode(ctx *generate.GenerateContext, nodeProvider
 	nodeProvider.InstallMisePackages(ctx, miseStep)
 
 	install := ctx.NewCommandStep("install:node")
+	install.UseBuildArgs("*")
 	install.AddInput(plan.NewStepInput(miseStep.Name()))
 	nodeProvider.InstallNodeDeps(ctx, install)
 
@@ -197,6 +216,7 @@ func (p *PhpProvider) DeployWithNode(ctx *generate.GenerateContext, nodeProvider
 	nodeProvider.PruneNodeDeps(ctx, prune)
 
 	build := ctx.NewCommandStep("build")
+	build.UseBuildArgs("*")
 	build.Inputs = []plan.Input{
 		plan.NewStepInput(composer.Name()),
 		plan.NewStepInput(install.Name(), plan.InputOptions{
@@ -396,20 +416,9 @@ func (p *PhpProvider) phpImagePackage(ctx *generate.GenerateContext) (*generate.
 	imageStep.AptPackages = append(imageStep.AptPackages, ctx.Config.BuildAptPackages...)
 	imageStep.AptPackages = append(imageStep.AptPackages, ctx.Config.Deploy.AptPackages...)
 
+	// The version in composer.json is found by the version hints of the resolver
 	php := imageStep.Default("php", DEFAULT_PHP_VERSION)
 
-	// Read composer.json to get the PHP version
-	if composerJson, err := p.readComposerJson(ctx); err == nil {
-		phpVersion := objx.New(composerJson).Get("require.php")
-		if phpVersion.IsStr() {
-			if strings.HasPrefix(phpVersion.Str(), "^") {
-				imageStep.Version(php, strings.TrimPrefix(phpVersion.Str(), "^"), "composer.json > require > php")
-			} else {
-				imageStep.Version(php, phpVersion.Str(), "composer.json > require > php")
-			}
-		}
-	}
-
 	// Ensure that the version is available on Docker Hub
 	imageStep.SetVersionAvailable(php, func(version string) bool {
 		image := getPhpImage(version)
@@ -48,3 +48,20 @@ func TestPhpProvider(t *testing.T) {
 		})
 	}
 }
+
+func TestLaravelReleaseCommand(t *testing.T) {
+	ctx := testingUtils.CreateGenerateContext(t, "../../../examples/php-laravel-12-react")
+	provider := PhpProvider{}
+	require.NoError(t, provider.Initialize(ctx))
+	require.NoError(t, provider.Plan(ctx))
+
+	// Migrations only run in the release command, not when the container starts
+	require.Equal(t, "php artisan migrate --force", ctx.Deploy.ReleaseCmd)
+	require.NotContains(t, startContainerScript, "artisan migrate")
+
+	ctx = testingUtils.CreateGenerateContext(t, "../../../examples/php-laravel-12-react")
+	ctx.Env.SetVariable("RAILPACK_SKIP_MIGRATIONS", "true")
+	require.NoError(t, provider.Initialize(ctx))
+	require.NoError(t, provider.Plan(ctx))
+	require.Empty(t, ctx.Deploy.ReleaseCmd)
+}
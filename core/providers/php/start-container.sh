@@ -2,13 +2,8 @@
 
 set -e
 
+# Migrations are run once per release by the release command, not on every container start
 if [ "$IS_LARAVEL" = "true" ]; then
-  if [ "$RAILPACK_SKIP_MIGRATIONS" != "true" ]; then
-    # Run migrations and seeding
-    echo "Running migrations and seeding database ..."
-    php artisan migrate --force
-  fi
-
   php artisan storage:link
   php artisan optimize:clear
   php artisan optimize
@@ -0,0 +1,122 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/unbindapp/railpack/core/generate"
+)
+
+const (
+	// ProtocolVersion is the version of the exec plugin protocol
+	ProtocolVersion = 1
+
+	ActionDetect = "detect"
+	ActionPlan   = "plan"
+
+	execTimeout = 2 * time.Minute
+)
+
+// Request is sent to an exec plugin on stdin
+type Request struct {
+	Version int        `json:"version"`
+	Action  string     `json:"action"`
+	App     RequestApp `json:"app"`
+	Env     RequestEnv `json:"env"`
+}
+
+type RequestApp struct {
+	// Path is the absolute path of the app source. It is empty when the app is not read from a local directory,
+	// such as an archive or a git commit
+	Path string `json:"path"`
+}
+
+// RequestEnv describes the environment of the build. Secret values are never sent to a plugin
+type RequestEnv struct {
+	Secrets   []string          `json:"secrets"`
+	BuildArgs map[string]string `json:"buildArgs"`
+
+	// Config are the RAILPACK_ prefixed variables
+	Config map[string]string `json:"config"`
+}
+
+// newRequest describes the app and environment of the context for the given action
+func newRequest(ctx *generate.GenerateContext, action string) *Request {
+	path, _ := ctx.App.LocalDir()
+
+	request := &Request{
+		Version: ProtocolVersion,
+		Action:  action,
+		App:     RequestApp{Path: path},
+		Env: RequestEnv{
+			Secrets:   []string{},
+			BuildArgs: map[string]string{},
+			Config:    map[string]string{},
+		},
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(ctx.Env.Variables)) {
+		if strings.HasPrefix(name, "RAILPACK_") {
+			request.Env.Config[name] = ctx.Env.Variables[name]
+			continue
+		}
+		request.Env.Secrets = append(request.Env.Secrets, name)
+	}
+	maps.Copy(request.Env.BuildArgs, ctx.Env.BuildArgs)
+
+	return request
+}
+
+// execDetect asks the plugin if it matches the app. The response is a detection score and its reasons
+func (p *PluginProvider) execDetect(ctx *generate.GenerateContext) (*generate.Detection, error) {
+	detection := &generate.Detection{}
+	if err := p.run(newRequest(ctx, ActionDetect), detection); err != nil {
+		return nil, err
+	}
+
+	return detection, nil
+}
+
+// execPlan asks the plugin for the plan fragment of the app
+func (p *PluginProvider) execPlan(ctx *generate.GenerateContext) (*Fragment, error) {
+	fragment := &Fragment{}
+	if err := p.run(newRequest(ctx, ActionPlan), fragment); err != nil {
+		return nil, err
+	}
+
+	return fragment, nil
+}
+
+// run sends the request to the plugin on stdin and decodes the JSON response from stdout
+func (p *PluginProvider) run(request *Request, response any) error {
+	input, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	runCtx, cancel := context.WithTimeout(context.Background(), execTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(runCtx, p.exec)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("plugin `%s` failed to %s: %w\n%s", p.Name(), request.Action, err, strings.TrimSpace(stderr.String()))
+	}
+
+	if err := json.Unmarshal(stdout.Bytes(), response); err != nil {
+		return fmt.Errorf("plugin `%s` returned an invalid %s response: %w", p.Name(), request.Action, err)
+	}
+
+	return nil
+}
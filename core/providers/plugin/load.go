@@ -0,0 +1,197 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/unbindapp/railpack/core/app"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// ExecPluginPrefix is stripped from the file name of an exec plugin to get the provider name
+	ExecPluginPrefix = "railpack-provider-"
+)
+
+// Load loads the plugins at the given paths. A path is a plugin file or a directory of plugins.
+// YAML files are declarative providers and executable files are exec plugins.
+// Exec plugins are only loaded when allowExec is set, since they run on the machine that plans the build
+func Load(paths []string, allowExec bool) ([]*PluginProvider, error) {
+	plugins := []*PluginProvider{}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load plugin %s: %w", path, err)
+		}
+
+		if !info.IsDir() {
+			plugin, err := loadFile(path, info, allowExec)
+			if err != nil {
+				return nil, err
+			}
+			if plugin == nil {
+				return nil, fmt.Errorf("plugin %s is not a YAML provider or an executable", path)
+			}
+
+			plugins = append(plugins, plugin)
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read plugin directory %s: %w", path, err)
+		}
+
+		// Files that are not plugins are skipped so that a directory can contain other files
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+
+			entryInfo, err := entry.Info()
+			if err != nil {
+				return nil, err
+			}
+
+			plugin, err := loadFile(filepath.Join(path, entry.Name()), entryInfo, allowExec)
+			if err != nil {
+				return nil, err
+			}
+			if plugin != nil {
+				plugins = append(plugins, plugin)
+			}
+		}
+	}
+
+	if err := checkDuplicates(plugins); err != nil {
+		return nil, err
+	}
+
+	return plugins, nil
+}
+
+// LoadFromApp loads the plugins at paths relative to the app. A path is a YAML file or a directory of YAML files.
+// Exec plugins are never loaded from the app, since its files are not trusted
+func LoadFromApp(a *app.App, paths []string) ([]*PluginProvider, error) {
+	plugins := []*PluginProvider{}
+
+	for _, path := range paths {
+		dirs, err := a.FindDirectories(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load plugin %s: %w", path, err)
+		}
+
+		files := []string{path}
+		isDir := len(dirs) > 0
+		if isDir {
+			files, err = a.FindFiles(filepath.ToSlash(filepath.Join(path, "*")))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read plugin directory %s: %w", path, err)
+			}
+		} else if !a.HasMatch(path) {
+			return nil, fmt.Errorf("failed to load plugin %s: file does not exist", path)
+		}
+
+		for _, file := range files {
+			ext := filepath.Ext(file)
+			if ext != ".yaml" && ext != ".yml" {
+				if a.IsFileExecutable(file) {
+					return nil, fmt.Errorf("exec plugin %s can only be loaded from a plugin directory", file)
+				}
+				if !isDir {
+					return nil, fmt.Errorf("plugin %s is not a YAML provider", file)
+				}
+				continue
+			}
+
+			data, err := a.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read plugin %s: %w", file, err)
+			}
+
+			plugin, err := parseSpec([]byte(data), file)
+			if err != nil {
+				return nil, err
+			}
+			plugins = append(plugins, plugin)
+		}
+	}
+
+	if err := checkDuplicates(plugins); err != nil {
+		return nil, err
+	}
+
+	return plugins, nil
+}
+
+func checkDuplicates(plugins []*PluginProvider) error {
+	seen := map[string]string{}
+	for _, plugin := range plugins {
+		if source, ok := seen[plugin.Name()]; ok {
+			return fmt.Errorf("plugin `%s` is defined in both %s and %s", plugin.Name(), source, plugin.source)
+		}
+		seen[plugin.Name()] = plugin.source
+	}
+
+	return nil
+}
+
+// loadFile loads a single plugin file. Returns nil if the file is not a plugin
+func loadFile(path string, info os.FileInfo, allowExec bool) (*PluginProvider, error) {
+	ext := filepath.Ext(path)
+	if ext == ".yaml" || ext == ".yml" {
+		return LoadSpec(path)
+	}
+
+	if info.Mode()&0111 == 0 {
+		return nil, nil
+	}
+
+	if !allowExec {
+		return nil, fmt.Errorf("exec plugin %s can only be loaded from a plugin directory", path)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), ext)
+	name = strings.TrimPrefix(name, ExecPluginPrefix)
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PluginProvider{
+		spec:   &Spec{Name: name},
+		exec:   absPath,
+		source: path,
+	}, nil
+}
+
+// LoadSpec loads a declarative provider from a YAML file
+func LoadSpec(path string) (*PluginProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugin %s: %w", path, err)
+	}
+
+	return parseSpec(data, path)
+}
+
+// parseSpec parses a declarative provider. The path is used to describe the plugin
+func parseSpec(data []byte, path string) (*PluginProvider, error) {
+	spec := &Spec{}
+	if err := yaml.UnmarshalStrict(data, spec); err != nil {
+		return nil, fmt.Errorf("error reading plugin %s as YAML: %w", path, err)
+	}
+
+	if err := spec.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid plugin %s: %w", path, err)
+	}
+
+	return &PluginProvider{
+		spec:   spec,
+		source: path,
+	}, nil
+}
@@ -0,0 +1,243 @@
+package plugin
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/unbindapp/railpack/core/generate"
+	"github.com/unbindapp/railpack/core/plan"
+)
+
+// Spec is a provider that is defined outside of Railpack.
+// Declarative providers are written as a YAML spec. Exec plugins return the fragment when planning
+type Spec struct {
+	Name             string     `yaml:"name" json:"name"`
+	Detect           DetectSpec `yaml:"detect" json:"detect"`
+	StartCommandHelp string     `yaml:"startCommandHelp" json:"startCommandHelp,omitempty"`
+	Fragment         `yaml:",inline"`
+}
+
+// DetectSpec describes when a declarative provider matches the app
+type DetectSpec struct {
+	// Files are glob patterns. The provider matches if any of them matches a file or directory in the app
+	Files []string `yaml:"files" json:"files,omitempty"`
+
+	// Score is the score of each matching pattern. Defaults to the score of a manifest file
+	Score int `yaml:"score" json:"score,omitempty"`
+}
+
+// Fragment is the part of the build plan that a plugin contributes
+type Fragment struct {
+	Packages         map[string]string `yaml:"packages" json:"packages,omitempty"`
+	BuildAptPackages []string          `yaml:"buildAptPackages" json:"buildAptPackages,omitempty"`
+	Caches           map[string]string `yaml:"caches" json:"caches,omitempty"`
+	Steps            []StepFragment    `yaml:"steps" json:"steps,omitempty"`
+	Deploy           DeployFragment    `yaml:"deploy" json:"deploy"`
+}
+
+// StepFragment is a command step. Each step builds on top of the previous one
+type StepFragment struct {
+	Name      string            `yaml:"name" json:"name"`
+	Commands  []string          `yaml:"commands" json:"commands,omitempty"`
+	Caches    []string          `yaml:"caches" json:"caches,omitempty"`
+	Variables map[string]string `yaml:"variables" json:"variables,omitempty"`
+	Secrets   []string          `yaml:"secrets" json:"secrets,omitempty"`
+}
+
+// DeployFragment configures the container that runs the app
+type DeployFragment struct {
+	StartCmd    string            `yaml:"startCommand" json:"startCommand,omitempty"`
+	ReleaseCmd  string            `yaml:"releaseCommand" json:"releaseCommand,omitempty"`
+	Variables   map[string]string `yaml:"variables" json:"variables,omitempty"`
+	Paths       []string          `yaml:"paths" json:"paths,omitempty"`
+	AptPackages []string          `yaml:"aptPackages" json:"aptPackages,omitempty"`
+
+	// Include are the paths of the last step that are copied into the deploy. Defaults to the app directory
+	Include []string `yaml:"include" json:"include,omitempty"`
+}
+
+// PluginProvider is a provider backed by a declarative spec or an exec plugin
+type PluginProvider struct {
+	spec     *Spec
+	exec     string
+	source   string
+	fragment *Fragment
+}
+
+func (p *PluginProvider) Name() string {
+	return p.spec.Name
+}
+
+// Source is the path the plugin was loaded from
+func (p *PluginProvider) Source() string {
+	return p.source
+}
+
+func (p *PluginProvider) Detect(ctx *generate.GenerateContext) (bool, error) {
+	detection, err := p.DetectScore(ctx)
+	return detection.Matched(), err
+}
+
+func (p *PluginProvider) DetectScore(ctx *generate.GenerateContext) (*generate.Detection, error) {
+	if p.exec != "" {
+		return p.execDetect(ctx)
+	}
+
+	score := p.spec.Detect.Score
+	if score == 0 {
+		score = generate.DetectionScoreManifest
+	}
+
+	detection := &generate.Detection{}
+	for _, pattern := range p.spec.Detect.Files {
+		detection.AddMatch(ctx, pattern, score)
+	}
+
+	return detection, nil
+}
+
+func (p *PluginProvider) Initialize(ctx *generate.GenerateContext) error {
+	if p.exec == "" {
+		p.fragment = &p.spec.Fragment
+		return nil
+	}
+
+	fragment, err := p.execPlan(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := fragment.Validate(); err != nil {
+		return fmt.Errorf("invalid plan from plugin `%s`: %w", p.Name(), err)
+	}
+
+	p.fragment = fragment
+	return nil
+}
+
+func (p *PluginProvider) Plan(ctx *generate.GenerateContext) error {
+	if p.fragment == nil {
+		return fmt.Errorf("plugin `%s` is not initialized", p.Name())
+	}
+
+	fragment := p.fragment
+	ctx.Logger.LogInfo("Using plugin %s from %s", p.Name(), p.source)
+
+	var miseStep *generate.MiseStepBuilder
+	if len(fragment.Packages) > 0 || len(fragment.BuildAptPackages) > 0 {
+		miseStep = ctx.GetMiseStepBuilder()
+
+		for _, name := range slices.Sorted(maps.Keys(fragment.Packages)) {
+			ref := miseStep.Default(name, fragment.Packages[name])
+			miseStep.Version(ref, fragment.Packages[name], fmt.Sprintf("%s plugin", p.Name()))
+		}
+
+		for _, pkg := range fragment.BuildAptPackages {
+			miseStep.AddSupportingAptPackage(pkg)
+		}
+	}
+
+	caches := map[string]string{}
+	for _, name := range slices.Sorted(maps.Keys(fragment.Caches)) {
+		caches[name] = ctx.Caches.AddCache(name, fragment.Caches[name])
+	}
+
+	previous := plan.NewImageInput(plan.RAILPACK_BUILDER_IMAGE)
+	if miseStep != nil {
+		previous = plan.NewStepInput(miseStep.Name())
+	}
+
+	var lastStep *generate.CommandStepBuilder
+	for _, stepFragment := range fragment.Steps {
+		step := ctx.NewCommandStep(stepFragment.Name)
+		step.AddInput(previous)
+
+		for _, cmd := range stepFragment.Commands {
+			command, err := plan.UnmarshalStringCommand([]byte(cmd))
+			if err != nil {
+				return fmt.Errorf("invalid command in step `%s` of plugin `%s`: %w", stepFragment.Name, p.Name(), err)
+			}
+			step.AddCommand(command)
+		}
+
+		for _, name := range stepFragment.Caches {
+			cacheName, ok := caches[name]
+			if !ok {
+				return fmt.Errorf("step `%s` of plugin `%s` uses unknown cache `%s`", stepFragment.Name, p.Name(), name)
+			}
+			step.AddCache(cacheName)
+		}
+
+		step.AddVariables(stepFragment.Variables)
+
+		if stepFragment.Secrets != nil {
+			step.Secrets = stepFragment.Secrets
+		}
+
+		previous = plan.NewStepInput(step.Name())
+		lastStep = step
+	}
+
+	ctx.Deploy.StartCmd = fragment.Deploy.StartCmd
+	ctx.Deploy.ReleaseCmd = fragment.Deploy.ReleaseCmd
+	maps.Copy(ctx.Deploy.Variables, fragment.Deploy.Variables)
+	ctx.Deploy.Paths = append(ctx.Deploy.Paths, fragment.Deploy.Paths...)
+
+	ctx.Deploy.Inputs = []plan.Input{
+		ctx.DefaultRuntimeInputWithPackages(fragment.Deploy.AptPackages),
+	}
+
+	if miseStep != nil && len(fragment.Packages) > 0 {
+		ctx.Deploy.Inputs = append(ctx.Deploy.Inputs, ctx.MiseRuntimeInput())
+	}
+
+	if lastStep == nil {
+		ctx.Deploy.Inputs = append(ctx.Deploy.Inputs, plan.NewLocalInput("."))
+		return nil
+	}
+
+	include := fragment.Deploy.Include
+	if len(include) == 0 {
+		include = []string{"."}
+	}
+	ctx.Deploy.Inputs = append(ctx.Deploy.Inputs, plan.NewStepInput(lastStep.Name(), plan.InputOptions{
+		Include: include,
+	}))
+
+	return nil
+}
+
+func (p *PluginProvider) StartCommandHelp() string {
+	if p.spec.StartCommandHelp != "" {
+		return p.spec.StartCommandHelp
+	}
+
+	return fmt.Sprintf("The %s plugin from %s did not set a start command. Set one in the plugin or with the RAILPACK_START_CMD environment variable", p.Name(), p.source)
+}
+
+// Validate checks that the spec has a name and a valid fragment
+func (s *Spec) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("plugin must have a name")
+	}
+
+	return s.Fragment.Validate()
+}
+
+// Validate checks that the steps have unique names
+func (f *Fragment) Validate() error {
+	names := map[string]bool{}
+	for _, step := range f.Steps {
+		if step.Name == "" {
+			return fmt.Errorf("all steps must have a name")
+		}
+
+		if names[step.Name] {
+			return fmt.Errorf("step `%s` is defined more than once", step.Name)
+		}
+		names[step.Name] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/unbindapp/railpack/core/app"
+	"github.com/unbindapp/railpack/core/config"
+	"github.com/unbindapp/railpack/core/generate"
+	"github.com/unbindapp/railpack/core/logger"
+	"github.com/unbindapp/railpack/core/plan"
+)
+
+const acmeSpec = `name: acme
+detect:
+  files: ["acme.toml"]
+caches:
+  acme: /root/.cache/acme
+steps:
+  - name: install
+    commands:
+      - COPY acme.toml acme.toml
+      - acme install
+    caches: [acme]
+    secrets: []
+  - name: build
+    commands:
+      - COPY . .
+      - acme build
+    variables:
+      ACME_ENV: production
+deploy:
+  startCommand: acme serve
+  variables:
+    ACME_ENV: production
+`
+
+const widgetPlugin = `#!/bin/sh
+request=$(cat)
+case "$request" in
+  *'"action":"detect"'*) echo '{"score": 30, "reasons": ["found widget.json"]}' ;;
+  *) echo '{"steps": [{"name": "build", "commands": ["widget build"]}], "deploy": {"startCommand": "widget run"}}' ;;
+esac
+`
+
+func createContext(t *testing.T, files map[string]string) *generate.GenerateContext {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, contents := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644))
+	}
+
+	userApp, err := app.NewApp(dir)
+	require.NoError(t, err)
+
+	return &generate.GenerateContext{
+		App:      userApp,
+		Env:      app.NewEnvironment(nil),
+		Config:   config.EmptyConfig(),
+		Steps:    []generate.StepBuilder{},
+		Deploy:   generate.NewDeployBuilder(),
+		Caches:   generate.NewCacheContext(),
+		Metadata: generate.NewMetadata(),
+		Logger:   logger.NewLogger(),
+	}
+}
+
+func writePlugins(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, contents := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0755))
+	}
+	return dir
+}
+
+func TestDeclarativeProvider(t *testing.T) {
+	dir := writePlugins(t, map[string]string{"acme.yaml": acmeSpec, "README.md": "docs"})
+
+	plugins, err := Load([]string{dir}, false)
+	require.Error(t, err, "README.md is executable but exec plugins are not allowed")
+
+	require.NoError(t, os.Chmod(filepath.Join(dir, "README.md"), 0644))
+	plugins, err = Load([]string{dir}, false)
+	require.NoError(t, err)
+	require.Len(t, plugins, 1)
+
+	provider := plugins[0]
+	require.Equal(t, "acme", provider.Name())
+
+	detection, err := provider.DetectScore(createContext(t, map[string]string{"main.py": ""}))
+	require.NoError(t, err)
+	require.False(t, detection.Matched())
+
+	ctx := createContext(t, map[string]string{"acme.toml": ""})
+	detection, err = provider.DetectScore(ctx)
+	require.NoError(t, err)
+	require.Equal(t, generate.DetectionScoreManifest, detection.Score)
+	require.Equal(t, []string{"found acme.toml"}, detection.Reasons)
+
+	require.NoError(t, provider.Initialize(ctx))
+	require.NoError(t, provider.Plan(ctx))
+
+	require.Len(t, ctx.Steps, 2)
+	install := ctx.Steps[0].(*generate.CommandStepBuilder)
+	require.Equal(t, "install", install.Name())
+	require.Equal(t, []plan.Input{plan.NewImageInput(plan.RAILPACK_BUILDER_IMAGE)}, install.Inputs)
+	require.Equal(t, []string{"acme"}, install.Caches)
+	require.Equal(t, []string{}, install.Secrets)
+	require.Len(t, install.Commands, 2)
+
+	build := ctx.Steps[1].(*generate.CommandStepBuilder)
+	require.Equal(t, []plan.Input{plan.NewStepInput("install")}, build.Inputs)
+	require.Equal(t, "production", build.Variables["ACME_ENV"])
+
+	require.Equal(t, "acme serve", ctx.Deploy.StartCmd)
+	require.Equal(t, "production", ctx.Deploy.Variables["ACME_ENV"])
+	require.Equal(t, []plan.Input{
+		plan.NewImageInput(plan.RAILPACK_RUNTIME_IMAGE),
+		plan.NewStepInput("build", plan.InputOptions{Include: []string{"."}}),
+	}, ctx.Deploy.Inputs)
+}
+
+func TestExecPlugin(t *testing.T) {
+	dir := writePlugins(t, map[string]string{"railpack-provider-widget": widgetPlugin})
+
+	_, err := Load([]string{filepath.Join(dir, "railpack-provider-widget")}, false)
+	require.Error(t, err)
+
+	plugins, err := Load([]string{dir}, true)
+	require.NoError(t, err)
+	require.Len(t, plugins, 1)
+
+	provider := plugins[0]
+	require.Equal(t, "widget", provider.Name())
+
+	ctx := createContext(t, map[string]string{"widget.json": "{}"})
+	detection, err := provider.DetectScore(ctx)
+	require.NoError(t, err)
+	require.Equal(t, &generate.Detection{Score: 30, Reasons: []string{"found widget.json"}}, detection)
+
+	require.NoError(t, provider.Initialize(ctx))
+	require.NoError(t, provider.Plan(ctx))
+
+	require.Len(t, ctx.Steps, 1)
+	require.Equal(t, "widget run", ctx.Deploy.StartCmd)
+}
+
+func TestLoadInvalidPlugins(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{name: "missing name", files: map[string]string{"a.yaml": "detect:\n  files: [a]\n"}},
+		{name: "unknown field", files: map[string]string{"a.yaml": "name: a\nstart: a\n"}},
+		{name: "duplicate step", files: map[string]string{"a.yaml": "name: a\nsteps:\n  - name: build\n  - name: build\n"}},
+		{name: "duplicate plugin", files: map[string]string{"a.yaml": "name: a\n", "b.yml": "name: a\n"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, contents := range tt.files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644))
+			}
+
+			_, err := Load([]string{dir}, true)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestLoadFromApp(t *testing.T) {
+	dir := writePlugins(t, map[string]string{"acme.yaml": acmeSpec})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("docs"), 0644))
+
+	userApp := app.NewAppFromFS("test", os.DirFS(dir))
+
+	plugins, err := LoadFromApp(userApp, []string{"."})
+	require.NoError(t, err)
+	require.Len(t, plugins, 1)
+	require.Equal(t, "acme", plugins[0].Name())
+	require.Equal(t, "acme.yaml", plugins[0].Source())
+
+	plugins, err = LoadFromApp(userApp, []string{"acme.yaml"})
+	require.NoError(t, err)
+	require.Len(t, plugins, 1)
+
+	_, err = LoadFromApp(userApp, []string{"missing.yaml"})
+	require.Error(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "railpack-provider-widget"), []byte(widgetPlugin), 0755))
+	_, err = LoadFromApp(userApp, []string{"."})
+	require.Error(t, err, "exec plugins cannot be loaded from the app")
+}
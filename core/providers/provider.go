@@ -2,6 +2,7 @@ package providers
 
 import (
 	"github.com/unbindapp/railpack/core/generate"
+	"github.com/unbindapp/railpack/core/plan"
 	"github.com/unbindapp/railpack/core/providers/deno"
 	"github.com/unbindapp/railpack/core/providers/golang"
 	"github.com/unbindapp/railpack/core/providers/java"
@@ -15,13 +16,30 @@ import (
 type Provider interface {
 	Name() string
 	Detect(ctx *generate.GenerateContext) (bool, error)
+	DetectScore(ctx *generate.GenerateContext) (*generate.Detection, error)
 	Initialize(ctx *generate.GenerateContext) error
 	Plan(ctx *generate.GenerateContext) error
 	StartCommandHelp() string
 }
 
+// AuxiliaryProvider is implemented by providers that can build part of an app that is deployed by
+// another provider (e.g. the Vite frontend of a Django app)
+type AuxiliaryProvider interface {
+	Provider
+
+	// PlanAuxiliary adds the install and build steps to the context and returns the inputs with
+	// the build outputs. These are merged into the deploy inputs of the primary provider
+	PlanAuxiliary(ctx *generate.GenerateContext) ([]plan.Input, error)
+}
+
+// ComposingProvider is implemented by providers that already build the apps of other providers
+// themselves (e.g. PHP builds the Node assets of a Laravel app)
+type ComposingProvider interface {
+	ComposesWith() []string
+}
+
 func GetLanguageProviders() []Provider {
-	// Order is important here. The first provider that returns true from Detect() will be used.
+	// The provider with the highest detection score is used. Order breaks ties, so the first provider wins.
 	return []Provider{
 		&php.PhpProvider{},
 		&golang.GoProvider{},
@@ -35,7 +53,12 @@ func GetLanguageProviders() []Provider {
 }
 
 func GetProvider(name string) Provider {
-	for _, provider := range GetLanguageProviders() {
+	return FindProvider(GetLanguageProviders(), name)
+}
+
+// FindProvider returns the provider with the given name
+func FindProvider(providers []Provider, name string) Provider {
+	for _, provider := range providers {
 		if provider.Name() == name {
 			return provider
 		}
@@ -43,3 +66,22 @@ func GetProvider(name string) Provider {
 
 	return nil
 }
+
+// WithPlugins returns the plugin providers followed by the language providers.
+// Plugins come first so that they win ties, and a plugin with the name of a language provider replaces it
+func WithPlugins(plugins []Provider) []Provider {
+	providers := []Provider{}
+	for _, plugin := range plugins {
+		if FindProvider(providers, plugin.Name()) == nil {
+			providers = append(providers, plugin)
+		}
+	}
+
+	for _, provider := range GetLanguageProviders() {
+		if FindProvider(providers, provider.Name()) == nil {
+			providers = append(providers, provider)
+		}
+	}
+
+	return providers
+}
@@ -40,8 +40,17 @@ func (p *PythonProvider) getDjangoStartCommand(ctx *generate.GenerateContext) st
 		return ""
 	}
 
+	// Migrations run once per release with the release command, not every time a container starts
 	ctx.Logger.LogInfo("Using Django app: %s", appName)
-	return fmt.Sprintf("python manage.py migrate && gunicorn %s:application", appName)
+	return fmt.Sprintf("gunicorn %s:application", appName)
+}
+
+func (p *PythonProvider) getDjangoReleaseCommand(ctx *generate.GenerateContext) string {
+	if p.getDjangoAppName(ctx) == "" {
+		return ""
+	}
+
+	return "python manage.py migrate"
 }
 
 func (p *PythonProvider) isDjango(ctx *generate.GenerateContext) bool {
@@ -9,16 +9,18 @@ import (
 
 func TestDjango(t *testing.T) {
 	tests := []struct {
-		name     string
-		path     string
-		appName  string
-		startCmd string
+		name       string
+		path       string
+		appName    string
+		startCmd   string
+		releaseCmd string
 	}{
 		{
-			name:     "django project",
-			path:     "../../../examples/python-django",
-			appName:  "mysite.wsgi",
-			startCmd: "python manage.py migrate && gunicorn mysite.wsgi:application",
+			name:       "django project",
+			path:       "../../../examples/python-django",
+			appName:    "mysite.wsgi",
+			startCmd:   "gunicorn mysite.wsgi:application",
+			releaseCmd: "python manage.py migrate",
 		},
 		{
 			name: "non-django project",
@@ -39,6 +41,9 @@ func TestDjango(t *testing.T) {
 
 			startCmd := provider.getDjangoStartCommand(ctx)
 			require.Equal(t, tt.startCmd, startCmd)
+
+			releaseCmd := provider.GetReleaseCommand(ctx)
+			require.Equal(t, tt.releaseCmd, releaseCmd)
 		})
 	}
 }
@@ -8,7 +8,6 @@ import (
 
 	"github.com/unbindapp/railpack/core/generate"
 	"github.com/unbindapp/railpack/core/plan"
-	"github.com/unbindapp/railpack/internal/utils"
 )
 
 const (
@@ -30,18 +29,25 @@ func (p *PythonProvider) Initialize(ctx *generate.GenerateContext) error {
 }
 
 func (p *PythonProvider) Detect(ctx *generate.GenerateContext) (bool, error) {
-	hasPython := ctx.App.HasMatch("main.py") ||
-		p.hasRequirements(ctx) ||
-		p.hasPyproject(ctx) ||
-		p.hasPipfile(ctx)
+	detection, err := p.DetectScore(ctx)
+	return detection.Matched(), err
+}
+
+func (p *PythonProvider) DetectScore(ctx *generate.GenerateContext) (*generate.Detection, error) {
+	detection := &generate.Detection{}
+	detection.AddMatch(ctx, "requirements.txt", generate.DetectionScoreManifest)
+	detection.AddMatch(ctx, "pyproject.toml", generate.DetectionScoreManifest)
+	detection.AddMatch(ctx, "Pipfile", generate.DetectionScoreManifest)
+	detection.AddMatch(ctx, "main.py", generate.DetectionScoreEntrypoint)
 
-	return hasPython, nil
+	return detection, nil
 }
 
 func (p *PythonProvider) Plan(ctx *generate.GenerateContext) error {
 	p.InstallMisePackages(ctx, ctx.GetMiseStepBuilder())
 
 	install := ctx.NewCommandStep("install")
+	install.UseBuildArgs("*")
 	install.AddInput(plan.NewStepInput(p.GetBuilderDeps(ctx).Name()))
 
 	install.Secrets = []string{}
@@ -64,10 +70,12 @@ func (p *PythonProvider) Plan(ctx *generate.GenerateContext) error {
 	p.addMetadata(ctx)
 
 	build := ctx.NewCommandStep("build")
+	build.UseBuildArgs("*")
 	build.AddInput(plan.NewStepInput(install.Name()))
 	build.AddCommand(plan.NewCopyCommand("."))
 
 	ctx.Deploy.StartCmd = p.GetStartCommand(ctx)
+	ctx.Deploy.ReleaseCmd = p.GetReleaseCommand(ctx)
 	maps.Copy(ctx.Deploy.Variables, p.GetPythonEnvVars(ctx))
 
 	installArtifacts := plan.NewStepInput(build.Name(), plan.InputOptions{
@@ -76,9 +84,7 @@ func (p *PythonProvider) Plan(ctx *generate.GenerateContext) error {
 
 	ctx.Deploy.Inputs = []plan.Input{
 		plan.NewStepInput(p.GetImageWithRuntimeDeps(ctx).Name()),
-		plan.NewStepInput(ctx.GetMiseStepBuilder().Name(), plan.InputOptions{
-			Include: ctx.GetMiseStepBuilder().GetOutputPaths(),
-		}),
+		ctx.MiseRuntimeInput(),
 		installArtifacts,
 		plan.NewStepInput(build.Name(), plan.InputOptions{
 			Include: []string{"."},
@@ -114,6 +120,14 @@ func (p *PythonProvider) GetStartCommand(ctx *generate.GenerateContext) string {
 	return startCommand
 }
 
+func (p *PythonProvider) GetReleaseCommand(ctx *generate.GenerateContext) string {
+	if p.isDjango(ctx) {
+		return p.getDjangoReleaseCommand(ctx)
+	}
+
+	return ""
+}
+
 func (p *PythonProvider) getMainPythonFile(ctx *generate.GenerateContext) string {
 	for _, file := range []string{"main.py", "app.py", "bot.py"} {
 		if ctx.App.HasMatch(file) {
@@ -290,26 +304,12 @@ func (p *PythonProvider) GetBuilderDeps(ctx *generate.GenerateContext) *generate
 }
 
 func (p *PythonProvider) InstallMisePackages(ctx *generate.GenerateContext, miseStep *generate.MiseStepBuilder) {
-	python := miseStep.Default("python", DEFAULT_PYTHON_VERSION)
-
-	if envVersion, varName := ctx.Env.GetConfigVariable("PYTHON_VERSION"); envVersion != "" {
-		miseStep.Version(python, envVersion, varName)
-	}
-
-	if versionFile, err := ctx.App.ReadFile(".python-version"); err == nil {
-		miseStep.Version(python, utils.ExtractSemverVersion(string(versionFile)), ".python-version")
-	}
-
-	if runtimeFile, err := ctx.App.ReadFile("runtime.txt"); err == nil {
-		miseStep.Version(python, utils.ExtractSemverVersion(string(runtimeFile)), "runtime.txt")
-	}
-
-	if pipfileVersion := parseVersionFromPipfile(ctx); pipfileVersion != "" {
-		miseStep.Version(python, pipfileVersion, "Pipfile")
-	}
+	// The version the app requests is found by the version hints of the resolver
+	miseStep.Default("python", DEFAULT_PYTHON_VERSION)
 
 	if p.hasPoetry(ctx) || p.hasUv(ctx) || p.hasPdm(ctx) || p.hasPipfile(ctx) {
-		miseStep.Default("pipx", "latest")
+		// pipx only installs the package manager, which is not needed at runtime
+		miseStep.DefaultBuildOnly("pipx", "latest")
 	}
 }
 
@@ -367,22 +367,6 @@ func (p *PythonProvider) usesDep(ctx *generate.GenerateContext, dep string) bool
 	return false
 }
 
-var pipfileVersionRegex = regexp.MustCompile(`(python_version|python_full_version)\s*=\s*['"]([0-9.]*)"?`)
-
-func parseVersionFromPipfile(ctx *generate.GenerateContext) string {
-	pipfile, err := ctx.App.ReadFile("Pipfile")
-	if err != nil {
-		return ""
-	}
-
-	matches := pipfileVersionRegex.FindStringSubmatch(string(pipfile))
-
-	if len(matches) > 2 {
-		return matches[2]
-	}
-	return ""
-}
-
 func (p *PythonProvider) hasRequirements(ctx *generate.GenerateContext) bool {
 	return ctx.App.HasMatch("requirements.txt")
 }
@@ -1,6 +1,8 @@
 package shell
 
 import (
+	"fmt"
+
 	"github.com/charmbracelet/log"
 	"github.com/unbindapp/railpack/core/generate"
 	"github.com/unbindapp/railpack/core/plan"
@@ -19,7 +21,24 @@ func (p *ShellProvider) Name() string {
 }
 
 func (p *ShellProvider) Detect(ctx *generate.GenerateContext) (bool, error) {
-	return getScript(ctx) != "", nil
+	detection, err := p.DetectScore(ctx)
+	return detection.Matched(), err
+}
+
+func (p *ShellProvider) DetectScore(ctx *generate.GenerateContext) (*generate.Detection, error) {
+	detection := &generate.Detection{}
+	script := getScript(ctx)
+	if script == "" {
+		return detection, nil
+	}
+
+	if script == StartScriptName {
+		detection.Add(generate.DetectionScoreFallback, fmt.Sprintf("found %s", script))
+	} else {
+		detection.Add(generate.DetectionScoreExplicit, fmt.Sprintf("found %s from RAILPACK_SHELL_SCRIPT", script))
+	}
+
+	return detection, nil
 }
 
 func (p *ShellProvider) Initialize(ctx *generate.GenerateContext) error {
@@ -40,12 +40,23 @@ func (p *StaticfileProvider) Initialize(ctx *generate.GenerateContext) error {
 }
 
 func (p *StaticfileProvider) Detect(ctx *generate.GenerateContext) (bool, error) {
-	rootDir, err := getRootDir(ctx)
-	if rootDir != "" && err == nil {
-		return true, nil
+	detection, err := p.DetectScore(ctx)
+	return detection.Matched(), err
+}
+
+func (p *StaticfileProvider) DetectScore(ctx *generate.GenerateContext) (*generate.Detection, error) {
+	detection := &generate.Detection{}
+
+	// Mirrors the order the root dir is found in
+	if rootDir, envVarName := ctx.Env.GetConfigVariable("STATIC_FILE_ROOT"); rootDir != "" {
+		detection.Add(generate.DetectionScoreExplicit, fmt.Sprintf("%s is set", envVarName))
+	} else if staticfileConfig, err := getStaticfileConfig(ctx); staticfileConfig != nil && err == nil {
+		detection.Add(generate.DetectionScoreManifest, fmt.Sprintf("found %s", StaticfileConfigName))
+	} else if !detection.AddMatch(ctx, "public", generate.DetectionScoreFallback) {
+		detection.AddMatch(ctx, "index.html", generate.DetectionScoreEntrypoint)
 	}
 
-	return false, nil
+	return detection, nil
 }
 
 func (p *StaticfileProvider) Plan(ctx *generate.GenerateContext) error {
@@ -61,9 +72,7 @@ func (p *StaticfileProvider) Plan(ctx *generate.GenerateContext) error {
 
 	ctx.Deploy.Inputs = []plan.Input{
 		ctx.DefaultRuntimeInput(),
-		plan.NewStepInput(miseStep.Name(), plan.InputOptions{
-			Include: miseStep.GetOutputPaths(),
-		}),
+		ctx.MiseRuntimeInput(),
 		plan.NewStepInput(setup.Name(), plan.InputOptions{
 			Include: []string{"."},
 		}),
@@ -0,0 +1,115 @@
+package resolver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// DefaultVersionCacheTTL is how long the versions listed by mise are reused by default
+	DefaultVersionCacheTTL = time.Hour
+
+	versionCacheDir = "versions"
+)
+
+// VersionCacheOptions configures the on-disk cache of the versions listed by mise
+type VersionCacheOptions struct {
+	// TTL is how long listed versions are reused. Versions are not cached when it is zero
+	TTL time.Duration
+
+	// Refresh lists the versions again even if they are cached, and updates the cache
+	Refresh bool
+}
+
+// versionCache stores the versions of each tool in a JSON file so that they are not listed again for every plan
+type versionCache struct {
+	dir string
+	ttl time.Duration
+
+	// Entries written before this time are stale
+	notBefore time.Time
+}
+
+type versionCacheEntry struct {
+	FetchedAt time.Time `json:"fetchedAt"`
+	Versions  []string  `json:"versions"`
+}
+
+// newVersionCache creates a cache in dir. Returns nil if caching is disabled
+func newVersionCache(dir string, options VersionCacheOptions) *versionCache {
+	if options.TTL <= 0 {
+		return nil
+	}
+
+	cache := &versionCache{
+		dir: filepath.Join(dir, versionCacheDir),
+		ttl: options.TTL,
+	}
+
+	if options.Refresh {
+		cache.notBefore = time.Now()
+	}
+
+	return cache
+}
+
+func (c *versionCache) path(pkg string) string {
+	return filepath.Join(c.dir, url.PathEscape(pkg)+".json")
+}
+
+// get returns the cached versions of a tool if they have not expired
+func (c *versionCache) get(pkg string) ([]string, bool) {
+	data, err := os.ReadFile(c.path(pkg))
+	if err != nil {
+		return nil, false
+	}
+
+	// A corrupt entry is treated as missing and overwritten
+	var entry versionCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || len(entry.Versions) == 0 {
+		return nil, false
+	}
+
+	if entry.FetchedAt.Before(c.notBefore) || time.Since(entry.FetchedAt) > c.ttl {
+		return nil, false
+	}
+
+	return entry.Versions, true
+}
+
+// put caches the versions of a tool. The file is replaced atomically so that concurrent plans never read a partial entry
+func (c *versionCache) put(pkg string, versions []string) error {
+	data, err := json.Marshal(versionCacheEntry{FetchedAt: time.Now(), Versions: versions})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create version cache directory: %w", err)
+	}
+
+	file, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write version cache: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write version cache: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write version cache: %w", err)
+	}
+
+	if err := os.Rename(file.Name(), c.path(pkg)); err != nil {
+		return fmt.Errorf("failed to write version cache: %w", err)
+	}
+
+	return nil
+}
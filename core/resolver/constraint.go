@@ -0,0 +1,511 @@
+package resolver
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ConstraintStyle is the syntax of a version constraint
+type ConstraintStyle string
+
+const (
+	// ConstraintStyleNpm is the range syntax of npm, e.g. `>=18 <21 || ^22`
+	ConstraintStyleNpm ConstraintStyle = "npm"
+
+	// ConstraintStylePep440 is the specifier syntax of Python, e.g. `>=3.9,<3.13` or `~=3.11`
+	ConstraintStylePep440 ConstraintStyle = "pep440"
+
+	// ConstraintStyleComposer is the constraint syntax of Composer, e.g. `^8.1 || ~8.3.0`
+	ConstraintStyleComposer ConstraintStyle = "composer"
+)
+
+// packageConstraintStyles are the packages whose versions are not written in the npm syntax
+var packageConstraintStyles = map[string]ConstraintStyle{
+	"python": ConstraintStylePep440,
+	"pipx":   ConstraintStylePep440,
+	"php":    ConstraintStyleComposer,
+}
+
+var (
+	plainVersionRegex = regexp.MustCompile(`^[vV]?\d+(\.\d+)*$`)
+	operatorRegex     = regexp.MustCompile(`(===|==|!=|~=|>=|<=|>|<|=|\^|~)\s+`)
+)
+
+// ConstraintStyleForPackage returns the constraint syntax used for the versions of a package
+func ConstraintStyleForPackage(name string) ConstraintStyle {
+	if style, ok := packageConstraintStyles[name]; ok {
+		return style
+	}
+	return ConstraintStyleNpm
+}
+
+// Constraint is a parsed version constraint.
+// A version satisfies the constraint if it satisfies every comparator of any of the alternatives
+type Constraint struct {
+	alternatives [][]comparator
+
+	// plain is set when the constraint is a version or a version prefix, such as `22` or `3.12.1`.
+	// Wildcards like `14.x` are ranges
+	plain bool
+}
+
+type comparator struct {
+	op      string
+	version semver
+
+	// prefix is set for `==` and `!=` comparators that match every version starting with the version, e.g. `==3.11.*`
+	prefix bool
+}
+
+// semver is a version with numeric release parts and an optional pre-release
+type semver struct {
+	parts []int
+	pre   string
+}
+
+// ParseConstraint parses a version constraint in the given syntax
+func ParseConstraint(constraint string, style ConstraintStyle) (*Constraint, error) {
+	raw := strings.TrimSpace(constraint)
+
+	if raw == "" || raw == "*" || raw == "latest" || plainVersionRegex.MatchString(raw) {
+		return &Constraint{plain: true}, nil
+	}
+
+	normalized := operatorRegex.ReplaceAllString(raw, "$1")
+
+	var alternatives []string
+	switch style {
+	case ConstraintStylePep440:
+		alternatives = []string{normalized}
+	case ConstraintStyleComposer:
+		alternatives = strings.Split(strings.ReplaceAll(normalized, "||", "|"), "|")
+	default:
+		alternatives = strings.Split(normalized, "||")
+	}
+
+	c := &Constraint{}
+	for _, alternative := range alternatives {
+		comparators, err := parseAlternative(strings.TrimSpace(alternative), style)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version constraint `%s`: %w", raw, err)
+		}
+		c.alternatives = append(c.alternatives, comparators)
+	}
+
+	return c, nil
+}
+
+// IsPlain returns true if the constraint is a version or a version prefix instead of a range
+func (c *Constraint) IsPlain() bool {
+	return c.plain
+}
+
+// HasUpperBound returns true if every alternative of the constraint has a highest version, e.g. `^18` but not `>=18`
+func (c *Constraint) HasUpperBound() bool {
+	if c.plain {
+		return false
+	}
+
+	for _, comparators := range c.alternatives {
+		bounded := false
+		for _, comp := range comparators {
+			if comp.op == "<" || comp.op == "<=" || comp.op == "=" {
+				bounded = true
+			}
+		}
+
+		if !bounded {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Check returns true if the version satisfies the constraint.
+// Pre-releases only satisfy a constraint that mentions a pre-release of the same version
+func (c *Constraint) Check(version string) bool {
+	v, ok := parseSemver(version)
+	if !ok {
+		return false
+	}
+
+	if c.plain {
+		return v.pre == ""
+	}
+
+	for _, comparators := range c.alternatives {
+		if satisfiesAll(v, comparators) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Best returns the highest version that satisfies the constraint
+func (c *Constraint) Best(versions []string) (string, bool) {
+	best := ""
+	var bestVersion semver
+
+	for _, version := range versions {
+		if !c.Check(version) {
+			continue
+		}
+
+		v, _ := parseSemver(version)
+		if best == "" || v.compare(bestVersion) > 0 {
+			best, bestVersion = version, v
+		}
+	}
+
+	return best, best != ""
+}
+
+// String returns the normalized form of the constraint, e.g. `>=18 <21 || >=22 <23`
+func (c *Constraint) String() string {
+	if c.plain {
+		return "*"
+	}
+
+	alternatives := []string{}
+	for _, comparators := range c.alternatives {
+		parts := []string{}
+		for _, comp := range comparators {
+			parts = append(parts, comp.String())
+		}
+		alternatives = append(alternatives, strings.Join(parts, " "))
+	}
+
+	return strings.Join(alternatives, " || ")
+}
+
+func (c comparator) String() string {
+	if c.prefix {
+		return fmt.Sprintf("%s%s.*", c.op, c.version)
+	}
+	return c.op + c.version.String()
+}
+
+func satisfiesAll(v semver, comparators []comparator) bool {
+	allowPre := v.pre == ""
+
+	for _, comp := range comparators {
+		if !comp.matches(v) {
+			return false
+		}
+
+		if v.pre != "" && comp.version.pre != "" && slices.Equal(comp.version.release(), v.release()) {
+			allowPre = true
+		}
+	}
+
+	return allowPre
+}
+
+func (c comparator) matches(v semver) bool {
+	if c.prefix {
+		hasPrefix := len(v.parts) >= len(c.version.parts) && slices.Equal(v.parts[:len(c.version.parts)], c.version.parts)
+		if c.op == "!=" {
+			return !hasPrefix
+		}
+		return hasPrefix
+	}
+
+	cmp := v.compare(c.version)
+	switch c.op {
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case "!=":
+		return cmp != 0
+	default:
+		return cmp == 0
+	}
+}
+
+// parseAlternative parses the comparators that must all be satisfied
+func parseAlternative(alternative string, style ConstraintStyle) ([]comparator, error) {
+	if alternative == "" {
+		return nil, fmt.Errorf("empty range")
+	}
+
+	// Hyphen ranges, e.g. `1.2 - 2.3.4`
+	if lower, upper, ok := strings.Cut(alternative, " - "); ok {
+		lowerComps, err := parseComparator(">="+strings.TrimSpace(lower), style)
+		if err != nil {
+			return nil, err
+		}
+		upperComps, err := parseComparator("<="+strings.TrimSpace(upper), style)
+		if err != nil {
+			return nil, err
+		}
+		return append(lowerComps, upperComps...), nil
+	}
+
+	var fields []string
+	if style == ConstraintStyleNpm {
+		fields = strings.Fields(alternative)
+	} else {
+		fields = strings.FieldsFunc(alternative, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		})
+	}
+
+	comparators := []comparator{}
+	for _, field := range fields {
+		comps, err := parseComparator(field, style)
+		if err != nil {
+			return nil, err
+		}
+		comparators = append(comparators, comps...)
+	}
+
+	return comparators, nil
+}
+
+// parseComparator expands a single comparator such as `^1.2`, `~=3.11` or `>=20` into primitive comparators
+func parseComparator(field string, style ConstraintStyle) ([]comparator, error) {
+	// Composer stability flags, e.g. `^8.1@stable`
+	if style == ConstraintStyleComposer {
+		field, _, _ = strings.Cut(field, "@")
+	}
+
+	op := ""
+	for _, candidate := range []string{"===", "==", "!=", "~=", ">=", "<=", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(field, candidate) {
+			op = candidate
+			break
+		}
+	}
+
+	p, ok := parsePartial(strings.TrimPrefix(field, op))
+	if !ok {
+		return nil, fmt.Errorf("invalid version `%s`", field)
+	}
+
+	if p.any {
+		switch op {
+		case "", "=", "==", ">=", "^", "~":
+			return []comparator{}, nil
+		default:
+			return nil, fmt.Errorf("invalid version `%s`", field)
+		}
+	}
+
+	lower := p.semver()
+
+	switch op {
+	case "^":
+		return []comparator{{op: ">=", version: lower}, {op: "<", version: p.caretUpper()}}, nil
+	case "~":
+		// Composer bumps the second to last given part (`~1.2` is `<2.0`), npm bumps the minor version
+		// (`~1.2` is `<1.3`) unless only the major version is given
+		index := 1
+		if len(p.parts) == 1 || (style == ConstraintStyleComposer && len(p.parts) == 2) {
+			index = 0
+		}
+		return []comparator{{op: ">=", version: lower}, {op: "<", version: p.bump(index)}}, nil
+	case "~=":
+		if len(p.parts) < 2 {
+			return nil, fmt.Errorf("`~=` requires at least two version parts in `%s`", field)
+		}
+		return []comparator{{op: ">=", version: lower}, {op: "<", version: p.bump(len(p.parts) - 2)}}, nil
+	case ">":
+		if p.isPartial(style) {
+			return []comparator{{op: ">=", version: p.bump(len(p.parts) - 1)}}, nil
+		}
+		return []comparator{{op: ">", version: lower}}, nil
+	case "<=":
+		if p.isPartial(style) {
+			return []comparator{{op: "<", version: p.bump(len(p.parts) - 1)}}, nil
+		}
+		return []comparator{{op: "<=", version: lower}}, nil
+	case ">=", "<":
+		return []comparator{{op: op, version: lower}}, nil
+	case "!=":
+		return []comparator{{op: "!=", version: lower, prefix: p.wildcard}}, nil
+	default:
+		// `==3.11.*` and partial versions like `1.2` or `1.x` match every version with the prefix
+		if p.wildcard || ((op == "" || op == "=") && p.isPartial(style)) {
+			return []comparator{{op: ">=", version: lower}, {op: "<", version: p.bump(len(p.parts) - 1)}}, nil
+		}
+		return []comparator{{op: "=", version: lower}}, nil
+	}
+}
+
+// partialVersion is a version that can leave out parts or end with a wildcard, e.g. `1.2` or `3.11.*`
+type partialVersion struct {
+	parts    []int
+	pre      string
+	wildcard bool
+	any      bool
+}
+
+func parsePartial(s string) (partialVersion, bool) {
+	s = strings.TrimLeft(strings.TrimSpace(s), "vV")
+
+	// Build metadata is ignored
+	s, _, _ = strings.Cut(s, "+")
+
+	pre := ""
+	if release, suffix, ok := strings.Cut(s, "-"); ok {
+		s, pre = release, suffix
+	}
+
+	// PEP 440 pre-releases are written directly after the release, e.g. `3.13.0rc1`
+	if i := strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= '0' && r <= '9') && r != '.' && r != 'x' && r != 'X' && r != '*'
+	}); i >= 0 {
+		suffix := strings.TrimLeft(s[i:], ".")
+		s = strings.TrimRight(s[:i], ".")
+
+		// Post releases are newer than the release, so they are treated as the release itself
+		if !strings.HasPrefix(suffix, "post") {
+			pre = suffix
+		}
+	}
+
+	if s == "" {
+		return partialVersion{}, false
+	}
+
+	p := partialVersion{pre: pre}
+	for _, part := range strings.Split(s, ".") {
+		if part == "x" || part == "X" || part == "*" {
+			p.wildcard = true
+			break
+		}
+
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return partialVersion{}, false
+		}
+		p.parts = append(p.parts, n)
+	}
+
+	p.any = len(p.parts) == 0
+	return p, true
+}
+
+// isPartial returns true if parts of the version are left out. PEP 440 and Composer pad missing parts with zeros
+func (p partialVersion) isPartial(style ConstraintStyle) bool {
+	if p.wildcard {
+		return true
+	}
+	return style == ConstraintStyleNpm && len(p.parts) < 3
+}
+
+func (p partialVersion) semver() semver {
+	return semver{parts: slices.Clone(p.parts), pre: p.pre}
+}
+
+// bump increments the part at index and drops the parts after it, e.g. bumping index 1 of `1.2.3` gives `1.3`
+func (p partialVersion) bump(index int) semver {
+	parts := slices.Clone(p.parts[:index+1])
+	parts[index]++
+	return semver{parts: parts}
+}
+
+// caretUpper returns the exclusive upper bound of a caret range, which bumps the first non-zero part
+func (p partialVersion) caretUpper() semver {
+	for i, part := range p.parts {
+		if part != 0 || i == len(p.parts)-1 {
+			return p.bump(i)
+		}
+	}
+	return p.bump(0)
+}
+
+func parseSemver(version string) (semver, bool) {
+	p, ok := parsePartial(version)
+	if !ok || p.wildcard || p.any {
+		return semver{}, false
+	}
+	return p.semver(), true
+}
+
+// compare compares two versions. Missing parts are zero and a pre-release is lower than its release
+func (v semver) compare(other semver) int {
+	for i := 0; i < max(len(v.parts), len(other.parts)); i++ {
+		a, b := 0, 0
+		if i < len(v.parts) {
+			a = v.parts[i]
+		}
+		if i < len(other.parts) {
+			b = other.parts[i]
+		}
+		if a != b {
+			return a - b
+		}
+	}
+
+	switch {
+	case v.pre == other.pre:
+		return 0
+	case v.pre == "":
+		return 1
+	case other.pre == "":
+		return -1
+	}
+
+	return comparePrerelease(v.pre, other.pre)
+}
+
+// release returns the release parts padded to three parts
+func (v semver) release() []int {
+	parts := slices.Clone(v.parts)
+	for len(parts) < 3 {
+		parts = append(parts, 0)
+	}
+	return parts
+}
+
+func (v semver) String() string {
+	parts := make([]string, len(v.parts))
+	for i, part := range v.parts {
+		parts[i] = strconv.Itoa(part)
+	}
+
+	version := strings.Join(parts, ".")
+	if v.pre != "" {
+		version += "-" + v.pre
+	}
+	return version
+}
+
+// comparePrerelease compares dot separated pre-release identifiers. Numeric identifiers are compared as numbers
+func comparePrerelease(a, b string) int {
+	aParts, bParts := strings.Split(a, "."), strings.Split(b, ".")
+
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNum, aErr := strconv.Atoi(aParts[i])
+		bNum, bErr := strconv.Atoi(bParts[i])
+
+		var cmp int
+		switch {
+		case aErr == nil && bErr == nil:
+			cmp = aNum - bNum
+		case aErr == nil:
+			cmp = -1
+		case bErr == nil:
+			cmp = 1
+		default:
+			cmp = strings.Compare(aParts[i], bParts[i])
+		}
+
+		if cmp != 0 {
+			return cmp
+		}
+	}
+
+	return len(aParts) - len(bParts)
+}
@@ -0,0 +1,79 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstraintBest(t *testing.T) {
+	node := []string{"14.21.3", "16.20.2", "18.0.0", "18.20.4", "20.18.1", "21.0.0", "22.12.0", "23.0.0-rc.1", "23.5.0"}
+	python := []string{"3.9.21", "3.11.11", "3.12.8", "3.13.0rc1", "3.13.1"}
+	php := []string{"7.4.33", "8.1.31", "8.2.27", "8.3.0", "8.3.15", "8.4.2"}
+
+	tests := []struct {
+		constraint string
+		style      ConstraintStyle
+		versions   []string
+		expected   string
+	}{
+		{constraint: ">=18 <21", style: ConstraintStyleNpm, versions: node, expected: "20.18.1"},
+		{constraint: ">= 18.0.0 < 21", style: ConstraintStyleNpm, versions: node, expected: "20.18.1"},
+		{constraint: "^18.0.0", style: ConstraintStyleNpm, versions: node, expected: "18.20.4"},
+		{constraint: "^16 || ^20", style: ConstraintStyleNpm, versions: node, expected: "20.18.1"},
+		{constraint: "~18.0", style: ConstraintStyleNpm, versions: node, expected: "18.0.0"},
+		{constraint: "16 - 20", style: ConstraintStyleNpm, versions: node, expected: "20.18.1"},
+		{constraint: "<=20", style: ConstraintStyleNpm, versions: node, expected: "20.18.1"},
+		{constraint: ">20", style: ConstraintStyleNpm, versions: node, expected: "23.5.0"},
+		{constraint: "=18", style: ConstraintStyleNpm, versions: node, expected: "18.20.4"},
+		{constraint: "18.x", style: ConstraintStyleNpm, versions: node, expected: "18.20.4"},
+		{constraint: "18.0.x", style: ConstraintStyleNpm, versions: node, expected: "18.0.0"},
+		{constraint: ">=23.0.0-rc.0 <23.1", style: ConstraintStyleNpm, versions: node, expected: "23.0.0-rc.1"},
+		{constraint: ">=22 <23.1", style: ConstraintStyleNpm, versions: node, expected: "22.12.0"},
+		{constraint: "~=3.11", style: ConstraintStylePep440, versions: python, expected: "3.13.1"},
+		{constraint: "~=3.11.0", style: ConstraintStylePep440, versions: python, expected: "3.11.11"},
+		{constraint: ">=3.9,<3.13", style: ConstraintStylePep440, versions: python, expected: "3.12.8"},
+		{constraint: "==3.11.*", style: ConstraintStylePep440, versions: python, expected: "3.11.11"},
+		{constraint: ">=3.9, !=3.12.*", style: ConstraintStylePep440, versions: python, expected: "3.13.1"},
+		{constraint: "^8.1 || ~8.3.0", style: ConstraintStyleComposer, versions: php, expected: "8.4.2"},
+		{constraint: "~8.2", style: ConstraintStyleComposer, versions: php, expected: "8.4.2"},
+		{constraint: "~8.2.0", style: ConstraintStyleComposer, versions: php, expected: "8.2.27"},
+		{constraint: ">=7.4 <8.2 | ^8.3", style: ConstraintStyleComposer, versions: php, expected: "8.4.2"},
+		{constraint: ">=7.4,<8.2", style: ConstraintStyleComposer, versions: php, expected: "8.1.31"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			constraint, err := ParseConstraint(tt.constraint, tt.style)
+			require.NoError(t, err)
+			require.False(t, constraint.IsPlain())
+
+			best, ok := constraint.Best(tt.versions)
+			require.True(t, ok)
+			require.Equal(t, tt.expected, best)
+		})
+	}
+}
+
+func TestParseConstraint(t *testing.T) {
+	for _, plain := range []string{"", "*", "latest", "22", "22.1", "3.13.1"} {
+		constraint, err := ParseConstraint(plain, ConstraintStyleNpm)
+		require.NoError(t, err, plain)
+		require.True(t, constraint.IsPlain(), plain)
+	}
+
+	_, err := ParseConstraint("lts", ConstraintStyleNpm)
+	require.Error(t, err)
+
+	constraint, err := ParseConstraint("^18.2 || >=22", ConstraintStyleNpm)
+	require.NoError(t, err)
+	require.Equal(t, ">=18.2 <19 || >=22", constraint.String())
+
+	_, ok := constraint.Best([]string{"18.1.0", "20.0.0"})
+	require.False(t, ok)
+	require.False(t, constraint.HasUpperBound())
+
+	constraint, err = ParseConstraint("^18.2 || 22.x", ConstraintStyleNpm)
+	require.NoError(t, err)
+	require.True(t, constraint.HasUpperBound())
+}
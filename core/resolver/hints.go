@@ -0,0 +1,267 @@
+package resolver
+
+import (
+	"bufio"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/unbindapp/railpack/internal/utils"
+)
+
+// VersionHint is a version of a package that the app requests, and where it was found
+type VersionHint struct {
+	Package string
+	Version string
+	Source  string
+
+	// Constraint is set when the version is a range that the package must satisfy instead of a requested version,
+	// e.g. `requires-python`. The default version is kept when it satisfies the range
+	Constraint bool
+}
+
+// HintFiles reads the files of the app that have version hints
+type HintFiles interface {
+	ReadFile(name string) (string, error)
+}
+
+// HintVariables reads the config variables that have version hints
+type HintVariables interface {
+	GetConfigVariable(name string) (string, string)
+}
+
+// hintFile parses the version hints of a file of the app
+type hintFile struct {
+	name  string
+	parse func(contents string) []VersionHint
+}
+
+// hintFiles are parsed in order. When multiple files have a hint for the same package, the last one is used
+var hintFiles = []hintFile{
+	{name: "package.json", parse: parsePackageJsonHints},
+	{name: ".node-version", parse: plainHint("node", ".node-version")},
+	{name: ".nvmrc", parse: plainHint("node", ".nvmrc")},
+	{name: "pyproject.toml", parse: parsePyprojectHints},
+	{name: ".python-version", parse: semverHint("python", ".python-version")},
+	{name: "runtime.txt", parse: semverHint("python", "runtime.txt")},
+	{name: "Pipfile", parse: parsePipfileHints},
+	{name: "go.mod", parse: parseGoModHints},
+	{name: "composer.json", parse: parseComposerJsonHints},
+	{name: ".sdkmanrc", parse: parseSdkmanrcHints},
+	{name: ".java-version", parse: semverHint("java", ".java-version")},
+}
+
+// hintVariables are the config variables that set the version of a package. Files take precedence over them,
+// except for go.mod which GO_VERSION overrides
+var hintVariables = []struct {
+	name           string
+	pkg            string
+	overridesFiles bool
+}{
+	{name: "NODE_VERSION", pkg: "node"},
+	{name: "BUN_VERSION", pkg: "bun"},
+	{name: "DENO_VERSION", pkg: "deno"},
+	{name: "PYTHON_VERSION", pkg: "python"},
+	{name: "GO_VERSION", pkg: "go", overridesFiles: true},
+	{name: "JDK_VERSION", pkg: "java"},
+	{name: "GRADLE_VERSION", pkg: "gradle"},
+}
+
+var (
+	pipfileVersionRegex = regexp.MustCompile(`(python_version|python_full_version)\s*=\s*['"]([0-9.]*)"?`)
+
+	// sdkmanCandidates are the SDKMAN candidates that are packages
+	sdkmanCandidates = map[string]string{
+		"java":   "java",
+		"gradle": "gradle",
+		"maven":  "maven",
+	}
+
+	// devEngineRuntimes are the runtimes of package.json devEngines that are packages
+	devEngineRuntimes = map[string]string{
+		"node": "node",
+		"bun":  "bun",
+		"deno": "deno",
+	}
+)
+
+// FindVersionHints finds the versions of packages that the app requests in its files and config variables
+func FindVersionHints(files HintFiles, variables HintVariables) []VersionHint {
+	hints := variableHints(variables, false)
+
+	for _, file := range hintFiles {
+		contents, err := files.ReadFile(file.name)
+		if err != nil {
+			continue
+		}
+
+		for _, hint := range file.parse(contents) {
+			if hint.Version = strings.TrimSpace(hint.Version); hint.Version != "" {
+				hints = append(hints, hint)
+			}
+		}
+	}
+
+	return append(hints, variableHints(variables, true)...)
+}
+
+// variableHints reads the config variables that either override files or are overridden by them
+func variableHints(variables HintVariables, overridesFiles bool) []VersionHint {
+	hints := []VersionHint{}
+	if variables == nil {
+		return hints
+	}
+
+	for _, variable := range hintVariables {
+		if variable.overridesFiles != overridesFiles {
+			continue
+		}
+
+		if version, name := variables.GetConfigVariable(variable.name); version != "" {
+			hints = append(hints, VersionHint{Package: variable.pkg, Version: version, Source: name})
+		}
+	}
+
+	return hints
+}
+
+// plainHint uses the contents of the file as the version, e.g. `v22.3.0`
+func plainHint(pkg, source string) func(string) []VersionHint {
+	return func(contents string) []VersionHint {
+		version := strings.TrimPrefix(strings.TrimSpace(contents), "v")
+		return []VersionHint{{Package: pkg, Version: version, Source: source}}
+	}
+}
+
+// semverHint uses the first version in the file, e.g. `python-3.11.4`
+func semverHint(pkg, source string) func(string) []VersionHint {
+	return func(contents string) []VersionHint {
+		return []VersionHint{{Package: pkg, Version: utils.ExtractSemverVersion(contents), Source: source}}
+	}
+}
+
+type devEngine struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+func parsePackageJsonHints(contents string) []VersionHint {
+	var packageJson struct {
+		Engines    map[string]string `json:"engines"`
+		Volta      map[string]string `json:"volta"`
+		DevEngines struct {
+			Runtime json.RawMessage `json:"runtime"`
+		} `json:"devEngines"`
+	}
+	if err := json.Unmarshal([]byte(contents), &packageJson); err != nil {
+		return nil
+	}
+
+	hints := []VersionHint{
+		{Package: "node", Version: packageJson.Engines["node"], Source: "package.json > engines > node"},
+	}
+
+	// The runtime can be a single runtime or a list of runtimes
+	var runtimes []devEngine
+	if err := json.Unmarshal(packageJson.DevEngines.Runtime, &runtimes); err != nil {
+		var runtime devEngine
+		if err := json.Unmarshal(packageJson.DevEngines.Runtime, &runtime); err == nil {
+			runtimes = []devEngine{runtime}
+		}
+	}
+
+	for _, runtime := range runtimes {
+		if pkg, ok := devEngineRuntimes[runtime.Name]; ok {
+			hints = append(hints, VersionHint{Package: pkg, Version: runtime.Version, Source: "package.json > devEngines > runtime"})
+		}
+	}
+
+	// Volta pins an exact version, so it is more specific than the ranges of engines
+	hints = append(hints, VersionHint{Package: "node", Version: packageJson.Volta["node"], Source: "package.json > volta > node"})
+
+	return hints
+}
+
+func parsePyprojectHints(contents string) []VersionHint {
+	var pyproject struct {
+		Project struct {
+			RequiresPython string `toml:"requires-python"`
+		} `toml:"project"`
+	}
+	if _, err := toml.Decode(contents, &pyproject); err != nil {
+		return nil
+	}
+
+	return []VersionHint{{Package: "python", Version: pyproject.Project.RequiresPython, Source: "pyproject.toml > project > requires-python", Constraint: true}}
+}
+
+func parsePipfileHints(contents string) []VersionHint {
+	matches := pipfileVersionRegex.FindStringSubmatch(contents)
+	if len(matches) <= 2 {
+		return nil
+	}
+
+	return []VersionHint{{Package: "python", Version: matches[2], Source: "Pipfile"}}
+}
+
+// parseGoModHints reads the go directive, and the toolchain directive which takes precedence over it
+func parseGoModHints(contents string) []VersionHint {
+	var goHint, toolchainHint VersionHint
+
+	scanner := bufio.NewScanner(strings.NewReader(contents))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+
+		switch fields[0] {
+		case "go":
+			goHint = VersionHint{Package: "go", Version: fields[1], Source: "go.mod"}
+		case "toolchain":
+			if fields[1] != "default" {
+				toolchainHint = VersionHint{Package: "go", Version: strings.TrimPrefix(fields[1], "go"), Source: "go.mod > toolchain"}
+			}
+		}
+	}
+
+	return []VersionHint{goHint, toolchainHint}
+}
+
+func parseComposerJsonHints(contents string) []VersionHint {
+	var composerJson struct {
+		Require map[string]any `json:"require"`
+	}
+	if err := json.Unmarshal([]byte(contents), &composerJson); err != nil {
+		return nil
+	}
+
+	version, _ := composerJson.Require["php"].(string)
+	return []VersionHint{{Package: "php", Version: version, Source: "composer.json > require > php"}}
+}
+
+// parseSdkmanrcHints reads the candidates of an SDKMAN config, e.g. `java=21.0.2-tem`
+func parseSdkmanrcHints(contents string) []VersionHint {
+	hints := []VersionHint{}
+
+	scanner := bufio.NewScanner(strings.NewReader(contents))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		candidate, version, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+
+		if pkg, ok := sdkmanCandidates[strings.TrimSpace(candidate)]; ok {
+			// SDKMAN versions include the vendor, e.g. `21.0.2-tem`
+			hints = append(hints, VersionHint{Package: pkg, Version: utils.ExtractSemverVersion(version), Source: ".sdkmanrc"})
+		}
+	}
+
+	return hints
+}
@@ -0,0 +1,280 @@
+package resolver
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testFiles map[string]string
+
+func (f testFiles) ReadFile(name string) (string, error) {
+	if contents, ok := f[name]; ok {
+		return contents, nil
+	}
+	return "", os.ErrNotExist
+}
+
+type testVariables map[string]string
+
+func (v testVariables) GetConfigVariable(name string) (string, string) {
+	if value, ok := v[name]; ok {
+		return value, "RAILPACK_" + name
+	}
+	return "", ""
+}
+
+func TestFindVersionHints(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    testFiles
+		expected []VersionHint
+	}{
+		{
+			name: "package.json",
+			files: testFiles{"package.json": `{
+				"engines": {"node": ">=18"},
+				"volta": {"node": "20.11.1"},
+				"devEngines": {"runtime": [{"name": "node", "version": "^20"}, {"name": "bun", "version": "1.1"}]}
+			}`},
+			expected: []VersionHint{
+				{Package: "node", Version: ">=18", Source: "package.json > engines > node"},
+				{Package: "node", Version: "^20", Source: "package.json > devEngines > runtime"},
+				{Package: "bun", Version: "1.1", Source: "package.json > devEngines > runtime"},
+				{Package: "node", Version: "20.11.1", Source: "package.json > volta > node"},
+			},
+		},
+		{
+			name:  "devEngines runtime object",
+			files: testFiles{"package.json": `{"devEngines": {"runtime": {"name": "deno", "version": "2.1"}}}`},
+			expected: []VersionHint{
+				{Package: "deno", Version: "2.1", Source: "package.json > devEngines > runtime"},
+			},
+		},
+		{
+			name:  "node version files",
+			files: testFiles{".node-version": "v22.3.0\n", ".nvmrc": "lts/iron"},
+			expected: []VersionHint{
+				{Package: "node", Version: "22.3.0", Source: ".node-version"},
+				{Package: "node", Version: "lts/iron", Source: ".nvmrc"},
+			},
+		},
+		{
+			name: "python",
+			files: testFiles{
+				"pyproject.toml":  "[project]\nname = \"app\"\nrequires-python = \">=3.10\"\n",
+				"Pipfile":         "[requires]\npython_version = \"3.11\"\n",
+				"runtime.txt":     "python-3.11.4\n",
+				".python-version": "3.12\n",
+			},
+			expected: []VersionHint{
+				{Package: "python", Version: ">=3.10", Source: "pyproject.toml > project > requires-python", Constraint: true},
+				{Package: "python", Version: "3.12", Source: ".python-version"},
+				{Package: "python", Version: "3.11.4", Source: "runtime.txt"},
+				{Package: "python", Version: "3.11", Source: "Pipfile"},
+			},
+		},
+		{
+			name:  "go.mod toolchain",
+			files: testFiles{"go.mod": "module example.com/app\n\ntoolchain go1.22.3\n\ngo 1.21\n"},
+			expected: []VersionHint{
+				{Package: "go", Version: "1.21", Source: "go.mod"},
+				{Package: "go", Version: "1.22.3", Source: "go.mod > toolchain"},
+			},
+		},
+		{
+			name:  "composer.json",
+			files: testFiles{"composer.json": `{"require": {"php": "^8.2", "laravel/framework": "^11.0"}}`},
+			expected: []VersionHint{
+				{Package: "php", Version: "^8.2", Source: "composer.json > require > php"},
+			},
+		},
+		{
+			name:  "java",
+			files: testFiles{".sdkmanrc": "# sdkman\njava=17.0.2-tem\ngradle=8.5\nkotlin=1.9.0\n", ".java-version": "21\n"},
+			expected: []VersionHint{
+				{Package: "java", Version: "17.0.2", Source: ".sdkmanrc"},
+				{Package: "gradle", Version: "8.5", Source: ".sdkmanrc"},
+				{Package: "java", Version: "21", Source: ".java-version"},
+			},
+		},
+		{
+			name:     "invalid files",
+			files:    testFiles{"package.json": `{`, "pyproject.toml": "[", "composer.json": `{"require": {"php": 8}}`},
+			expected: []VersionHint{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FindVersionHints(tt.files, testVariables{}))
+		})
+	}
+}
+
+func TestVersionHintsPrecedence(t *testing.T) {
+	resolver := newTestResolver(t)
+	resolver.SetPreviousVersion("node", "18")
+	resolver.SetVersionHints(FindVersionHints(
+		testFiles{"package.json": `{"engines": {"node": "22"}}`, ".python-version": "3.12"},
+		testVariables{"PYTHON_VERSION": "3.13"},
+	))
+
+	node := resolver.Default("node", "23")
+	assert.Equal(t, "22", resolver.Get("node").Version)
+	assert.Equal(t, "package.json > engines > node", resolver.Get("node").Source)
+
+	// Version files take precedence over config variables
+	resolver.Default("python", "3.11")
+	assert.Equal(t, "3.12", resolver.Get("python").Version)
+	assert.Equal(t, ".python-version", resolver.Get("python").Source)
+
+	// Hints are only used for packages that are added
+	resolver.Default("go", "1.23")
+	assert.Equal(t, DefaultSource, resolver.Get("go").Source)
+
+	// Providers can still change the version after the hints
+	resolver.Version(node, "23", "railpack.json")
+	resolved, err := resolver.ResolvePackages()
+	require.NoError(t, err)
+	assert.Len(t, resolved["node"].Requests, 4)
+}
+
+// TestVersionHintsOrder pins which hint is used for each package when several of them set a version
+func TestVersionHintsOrder(t *testing.T) {
+	tests := []struct {
+		name      string
+		pkg       string
+		files     testFiles
+		variables testVariables
+		expected  []string
+	}{
+		{
+			name: "node",
+			pkg:  "node",
+			files: testFiles{
+				"package.json":  `{"engines": {"node": "18"}, "devEngines": {"runtime": {"name": "node", "version": "19"}}, "volta": {"node": "20.1.0"}}`,
+				".node-version": "21",
+				".nvmrc":        "22",
+			},
+			variables: testVariables{"NODE_VERSION": "23"},
+			expected:  []string{"RAILPACK_NODE_VERSION", "package.json > engines > node", "package.json > devEngines > runtime", "package.json > volta > node", ".node-version", ".nvmrc"},
+		},
+		{
+			name:      "bun",
+			pkg:       "bun",
+			files:     testFiles{"package.json": `{"devEngines": {"runtime": {"name": "bun", "version": "1.1"}}}`},
+			variables: testVariables{"BUN_VERSION": "1.2"},
+			expected:  []string{"RAILPACK_BUN_VERSION", "package.json > devEngines > runtime"},
+		},
+		{
+			name:      "deno",
+			pkg:       "deno",
+			files:     testFiles{"package.json": `{"devEngines": {"runtime": {"name": "deno", "version": "2.1"}}}`},
+			variables: testVariables{"DENO_VERSION": "2.2"},
+			expected:  []string{"RAILPACK_DENO_VERSION", "package.json > devEngines > runtime"},
+		},
+		{
+			name: "python",
+			pkg:  "python",
+			files: testFiles{
+				".python-version": "3.10",
+				"runtime.txt":     "python-3.11.4",
+				"Pipfile":         "[requires]\npython_version = \"3.12\"\n",
+			},
+			variables: testVariables{"PYTHON_VERSION": "3.13"},
+			expected:  []string{"RAILPACK_PYTHON_VERSION", ".python-version", "runtime.txt", "Pipfile"},
+		},
+		{
+			name:      "go",
+			pkg:       "go",
+			files:     testFiles{"go.mod": "module example.com/app\n\ngo 1.21\n\ntoolchain go1.22.3\n"},
+			variables: testVariables{"GO_VERSION": "1.24"},
+			expected:  []string{"go.mod", "go.mod > toolchain", "RAILPACK_GO_VERSION"},
+		},
+		{
+			name:      "java",
+			pkg:       "java",
+			files:     testFiles{".sdkmanrc": "java=17.0.2-tem\n", ".java-version": "21"},
+			variables: testVariables{"JDK_VERSION": "11"},
+			expected:  []string{"RAILPACK_JDK_VERSION", ".sdkmanrc", ".java-version"},
+		},
+		{
+			name:      "gradle",
+			pkg:       "gradle",
+			files:     testFiles{".sdkmanrc": "gradle=8.5\n"},
+			variables: testVariables{"GRADLE_VERSION": "7"},
+			expected:  []string{"RAILPACK_GRADLE_VERSION", ".sdkmanrc"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := newTestResolver(t)
+			resolver.SetVersionHints(FindVersionHints(tt.files, tt.variables))
+			resolver.Default(tt.pkg, "1")
+
+			// The requests are in order of precedence, so the last one is used
+			sources := []string{}
+			for _, request := range resolver.Get(tt.pkg).Requests[1:] {
+				sources = append(sources, request.Source)
+			}
+			assert.Equal(t, tt.expected, sources)
+			assert.Equal(t, tt.expected[len(tt.expected)-1], resolver.Get(tt.pkg).Source)
+		})
+	}
+}
+
+func TestRequiresPythonConstraint(t *testing.T) {
+	tests := []struct {
+		name           string
+		files          testFiles
+		previous       string
+		expected       string
+		expectedSource string
+	}{
+		{
+			name:           "default satisfies the range",
+			files:          testFiles{"pyproject.toml": "[project]\nrequires-python = \">=3.11\""},
+			expected:       "3.11.11",
+			expectedSource: DefaultSource,
+		},
+		{
+			name:           "default is outside of the range",
+			files:          testFiles{"pyproject.toml": "[project]\nrequires-python = \">=3.12,<3.13\""},
+			expected:       "3.12.8",
+			expectedSource: "pyproject.toml > project > requires-python",
+		},
+		{
+			name:           "previous version is outside of the range",
+			files:          testFiles{"pyproject.toml": "[project]\nrequires-python = \"~=3.13.0\""},
+			previous:       "3.12",
+			expected:       "3.13.1",
+			expectedSource: "pyproject.toml > project > requires-python",
+		},
+		{
+			name:           "requested version takes precedence",
+			files:          testFiles{"pyproject.toml": "[project]\nrequires-python = \">=3.12\"", ".python-version": "3.11"},
+			expected:       "3.11.11",
+			expectedSource: ".python-version",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := newTestResolver(t)
+			if tt.previous != "" {
+				resolver.SetPreviousVersion("python", tt.previous)
+			}
+			resolver.SetVersionHints(FindVersionHints(tt.files, nil))
+			resolver.Default("python", "3.11")
+
+			resolved, err := resolver.ResolvePackages()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, *resolved["python"].ResolvedVersion)
+			assert.Equal(t, tt.expectedSource, resolved["python"].Source)
+		})
+	}
+}
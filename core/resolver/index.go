@@ -0,0 +1,146 @@
+package resolver
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/unbindapp/railpack/internal/utils"
+)
+
+const (
+	// VersionIndexVersion is the version of the version index format
+	VersionIndexVersion = 1
+)
+
+var stableVersionRegex = regexp.MustCompile(`^\d+(\.\d+)*$`)
+
+// VersionIndex lists the available versions of tools so that versions can be resolved without network access
+type VersionIndex struct {
+	Version int `json:"version"`
+
+	// Tools are the available versions of each tool, from oldest to newest
+	Tools map[string][]string `json:"tools"`
+}
+
+func NewVersionIndex() *VersionIndex {
+	return &VersionIndex{
+		Version: VersionIndexVersion,
+		Tools:   map[string][]string{},
+	}
+}
+
+// ReadVersionIndex reads a version index from a JSON file
+func ReadVersionIndex(path string) (*VersionIndex, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read version index %s: %w", path, err)
+	}
+
+	index := NewVersionIndex()
+	if err := json.Unmarshal(data, index); err != nil {
+		return nil, fmt.Errorf("error reading version index %s as JSON: %w", path, err)
+	}
+
+	if index.Version > VersionIndexVersion {
+		return nil, fmt.Errorf("version index %s has version %d, but this version of Railpack only supports up to version %d", path, index.Version, VersionIndexVersion)
+	}
+
+	for _, tool := range slices.Collect(maps.Keys(index.Tools)) {
+		index.Set(tool, index.Tools[tool])
+	}
+
+	return index, nil
+}
+
+// Marshal serializes the index with a trailing newline
+func (i *VersionIndex) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(i, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
+
+// Set replaces the versions of a tool. The versions are sorted from oldest to newest
+func (i *VersionIndex) Set(tool string, versions []string) {
+	versions = slices.Clone(versions)
+	slices.SortStableFunc(versions, compareVersions)
+	i.Tools[tool] = slices.Compact(versions)
+}
+
+// LatestVersion gets the latest stable version of a tool matching the version, like `mise latest`
+func (i *VersionIndex) LatestVersion(pkg, version string) (string, error) {
+	versions, err := i.AllVersions(pkg, version)
+	if err != nil {
+		return "", err
+	}
+
+	for j := len(versions) - 1; j >= 0; j-- {
+		if stableVersionRegex.MatchString(versions[j]) {
+			return versions[j], nil
+		}
+	}
+
+	return versions[len(versions)-1], nil
+}
+
+// AllVersions gets the versions of a tool matching the version, like `mise ls-remote`
+func (i *VersionIndex) AllVersions(pkg, version string) ([]string, error) {
+	available, ok := i.Tools[pkg]
+	if !ok {
+		return nil, fmt.Errorf("%w: `%s` is not in the version index", ErrPackageNotFound, pkg)
+	}
+
+	// Aliases like `lts` have no numeric prefix, but are not the latest version either
+	prefix := utils.ExtractSemverVersion(version)
+	if prefix == "" && !slices.Contains([]string{"", "latest", "*"}, strings.TrimSpace(version)) {
+		return nil, fmt.Errorf("version %s of %s is an alias that the version index can not resolve", version, pkg)
+	}
+
+	versions := []string{}
+	for _, v := range available {
+		if strings.Contains(v, "RC") {
+			continue
+		}
+
+		if prefix == "" || v == prefix || strings.HasPrefix(v, prefix+".") {
+			versions = append(versions, v)
+		}
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("failed to resolve version %s of %s from the version index", version, pkg)
+	}
+
+	return versions, nil
+}
+
+// compareVersions compares versions by their dot separated parts. Numeric parts are compared as numbers
+func compareVersions(a, b string) int {
+	aParts, bParts := strings.Split(a, "."), strings.Split(b, ".")
+
+	for j := 0; j < len(aParts) && j < len(bParts); j++ {
+		aNum, aErr := strconv.Atoi(aParts[j])
+		bNum, bErr := strconv.Atoi(bParts[j])
+
+		var cmp int
+		if aErr == nil && bErr == nil {
+			cmp = aNum - bNum
+		} else {
+			cmp = strings.Compare(aParts[j], bParts[j])
+		}
+
+		if cmp != 0 {
+			return cmp
+		}
+	}
+
+	return len(aParts) - len(bParts)
+}
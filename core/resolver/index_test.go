@@ -0,0 +1,80 @@
+package resolver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "versions.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"version": 1,
+		"tools": {
+			"node": ["22.10.0", "18.20.0", "22.3.0", "23.0.0-rc.1", "22.9.0", "22.3.0"],
+			"python": ["3.13.0", "3.13.1rc1", "3.13.1RC2"]
+		}
+	}`), 0644))
+
+	index, err := ReadVersionIndex(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"18.20.0", "22.3.0", "22.9.0", "22.10.0", "23.0.0-rc.1"}, index.Tools["node"])
+
+	tests := []struct {
+		pkg      string
+		version  string
+		expected string
+	}{
+		{pkg: "node", version: "22", expected: "22.10.0"},
+		{pkg: "node", version: "22.3", expected: "22.3.0"},
+		{pkg: "node", version: "latest", expected: "22.10.0"},
+		{pkg: "node", version: "23", expected: "23.0.0-rc.1"},
+		{pkg: "python", version: "3.13", expected: "3.13.0"},
+	}
+
+	for _, tt := range tests {
+		latest, err := index.LatestVersion(tt.pkg, tt.version)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, latest, "%s@%s", tt.pkg, tt.version)
+	}
+
+	versions, err := index.AllVersions("node", "22")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"22.3.0", "22.9.0", "22.10.0"}, versions)
+
+	_, err = index.LatestVersion("node", "2")
+	require.Error(t, err)
+
+	_, err = index.LatestVersion("ruby", "3")
+	require.Error(t, err)
+
+	// Aliases are left to other version sources instead of matching every version
+	_, err = index.LatestVersion("node", "lts")
+	require.ErrorContains(t, err, "alias")
+	_, err = index.AllVersions("node", "lts/iron")
+	require.ErrorContains(t, err, "alias")
+}
+
+func TestResolverWithVersionIndex(t *testing.T) {
+	index := NewVersionIndex()
+	index.Set("node", []string{"20.1.0", "22.3.0"})
+	index.Set("php", []string{"8.2.1", "8.2.5", "8.3.0"})
+
+	resolver, err := NewResolver(t.TempDir())
+	require.NoError(t, err)
+	resolver.SetVersionSource(index)
+
+	resolver.Default("node", "22")
+	php := resolver.Default("php", "8.2")
+	resolver.SetVersionAvailable(php, func(version string) bool {
+		return version != "8.2.5"
+	})
+
+	resolvedPackages, err := resolver.ResolvePackages()
+	require.NoError(t, err)
+	assert.Equal(t, "22.3.0", *resolvedPackages["node"].ResolvedVersion)
+	assert.Equal(t, "8.2.1", *resolvedPackages["php"].ResolvedVersion)
+}
@@ -1,21 +1,29 @@
 package resolver
 
 import (
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/charmbracelet/log"
-	"github.com/unbindapp/railpack/core/mise"
 )
 
 const (
-	DefaultSource = "railpack default"
+	DefaultSource         = "railpack default"
+	PreviousVersionSource = "previous installed version"
 )
 
 type Resolver struct {
-	mise             *mise.Mise
+	versions         VersionSource
 	packages         map[string]*RequestedPackage
 	previousVersions map[string]string
+	hints            []VersionHint
+
+	lockedPackages map[string]*ResolvedPackage
+	frozen         bool
 }
 
 type RequestedPackage struct {
@@ -23,6 +31,26 @@ type RequestedPackage struct {
 	Version            string
 	Source             string
 	IsVersionAvailable func(version string) bool
+
+	// ConstraintStyle is the syntax of the requested version
+	ConstraintStyle ConstraintStyle
+
+	// Requests are all versions that were requested for the package, in order. The last one is used
+	Requests []VersionRequest
+
+	// FixedVersion is used as the resolved version of packages that are not installed by mise, e.g. apt packages
+	FixedVersion string
+
+	// Constraint is a range that the resolved version must satisfy, and ConstraintSource is where it came from.
+	// A default or previous version that is outside of the range is replaced by the best version in the range
+	Constraint       string
+	ConstraintSource string
+}
+
+// VersionRequest is a version that was requested for a package and where it came from
+type VersionRequest struct {
+	Version string `json:"version"`
+	Source  string `json:"source"`
 }
 
 type ResolvedPackage struct {
@@ -30,6 +58,12 @@ type ResolvedPackage struct {
 	RequestedVersion *string `json:"requestedVersion,omitempty"`
 	ResolvedVersion  *string `json:"resolvedVersion,omitempty"`
 	Source           string  `json:"source"`
+
+	// Constraint is the normalized range the version was resolved from. It is empty for plain versions
+	Constraint string `json:"constraint,omitempty"`
+
+	// Requests are all versions that were requested for the package and where they came from
+	Requests []VersionRequest `json:"requests,omitempty"`
 }
 
 type PackageRef struct {
@@ -38,78 +72,313 @@ type PackageRef struct {
 
 func NewRequestedPackage(name, defaultVersion string) *RequestedPackage {
 	return &RequestedPackage{
-		Name:    name,
-		Version: defaultVersion,
-		Source:  DefaultSource,
+		Name:            name,
+		Version:         defaultVersion,
+		Source:          DefaultSource,
+		ConstraintStyle: ConstraintStyleForPackage(name),
+		Requests:        []VersionRequest{{Version: defaultVersion, Source: DefaultSource}},
 	}
 }
 
 func (p *RequestedPackage) SetVersion(version, source string) *RequestedPackage {
 	p.Version = version
 	p.Source = source
+	p.Requests = append(p.Requests, VersionRequest{Version: version, Source: source})
 	return p
 }
 
+// NewResolver creates a resolver that uses mise to find versions.
+// Mise is only installed once a version has to be resolved
 func NewResolver(miseDir string) (*Resolver, error) {
-	mise, err := mise.New(miseDir)
-	if err != nil {
-		return nil, err
-	}
-
 	return &Resolver{
-		mise:             mise,
+		versions:         NewMiseSource(miseDir, VersionCacheOptions{}),
 		packages:         make(map[string]*RequestedPackage),
 		previousVersions: make(map[string]string),
 	}, nil
 }
 
+// LockDriftError lists the differences between the requested packages and the locked ones
+type LockDriftError struct {
+	Changes []string
+}
+
+func (e *LockDriftError) Error() string {
+	return "packages differ from the lockfile:\n  " + strings.Join(e.Changes, "\n  ")
+}
+
 func (r *Resolver) ResolvePackages() (map[string]*ResolvedPackage, error) {
 	resolvedPackages := make(map[string]*ResolvedPackage)
 
-	for name, pkg := range r.packages {
-		fuzzyVersion := resolveToFuzzyVersion(pkg.Version)
+	if err := r.checkLockDrift(); err != nil {
+		return nil, err
+	}
 
-		var latestVersion string
+	names := slices.Sorted(maps.Keys(r.packages))
+	results := make([]*ResolvedPackage, len(names))
+	errs := make([]error, len(names))
 
-		// If there is a custom version validator, we get possible versions and pick the latest one that matches
-		if pkg.IsVersionAvailable != nil {
-			versions, err := r.mise.GetAllVersions(name, fuzzyVersion)
-			if err != nil {
-				return nil, err
+	// Packages are resolved concurrently, since each one can take a network request or a mise process
+	var wg sync.WaitGroup
+	for i, name := range names {
+		pkg := r.packages[name]
+
+		// A locked version is reused as long as the package is requested with the same version
+		if locked := r.lockedVersion(pkg); locked != "" {
+			log.Debugf("Using locked package version %s %s", name, locked)
+
+			results[i] = &ResolvedPackage{
+				Name:             name,
+				RequestedVersion: &pkg.Version,
+				ResolvedVersion:  &locked,
+				Source:           pkg.Source,
+				Requests:         pkg.Requests,
 			}
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
 
-			for i := len(versions) - 1; i >= 0; i-- {
-				if pkg.IsVersionAvailable(versions[i]) {
-					latestVersion = versions[i]
-					break
-				}
+			resolvedPkg, err := r.resolvePackage(pkg)
+			if err != nil {
+				errs[i] = err
+				return
 			}
 
-			if latestVersion == "" {
-				return nil, fmt.Errorf("no version available for %s %s", name, pkg.Version)
+			log.Debugf("Resolved package version %s %s to %s from %s", name, pkg.Version, *resolvedPkg.ResolvedVersion, pkg.Source)
+			results[i] = resolvedPkg
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	for i, name := range names {
+		resolvedPackages[name] = results[i]
+	}
+
+	return resolvedPackages, nil
+}
+
+// resolvePackage resolves the requested version of a package.
+// Ranges are resolved to the highest available version that satisfies them. Ranges without an upper bound, such as
+// `>=18`, use the default version of the package instead if it satisfies them. Plain versions and versions that are
+// not a constraint, such as `lts`, are resolved by the version source.
+// A default or previous version that does not satisfy the constraint of the package is replaced by the best
+// version that does
+func (r *Resolver) resolvePackage(pkg *RequestedPackage) (*ResolvedPackage, error) {
+	resolvedPkg := &ResolvedPackage{
+		Name:             pkg.Name,
+		RequestedVersion: &pkg.Version,
+		Source:           pkg.Source,
+		Requests:         pkg.Requests,
+	}
+
+	if pkg.FixedVersion != "" {
+		version := pkg.FixedVersion
+		resolvedPkg.ResolvedVersion = &version
+		return resolvedPkg, nil
+	}
+
+	version, normalized, err := r.resolveVersion(pkg)
+	if err != nil {
+		return nil, err
+	}
+	resolvedPkg.ResolvedVersion = &version
+	resolvedPkg.Constraint = normalized
+
+	if pkg.Constraint == "" {
+		return resolvedPkg, nil
+	}
+
+	constraint, err := ParseConstraint(pkg.Constraint, pkg.ConstraintStyle)
+	if err != nil {
+		log.Warnf("Ignoring %s from %s: %s", pkg.Constraint, pkg.ConstraintSource, err)
+		return resolvedPkg, nil
+	}
+
+	if !constraint.IsPlain() {
+		resolvedPkg.Constraint = constraint.String()
+	}
+
+	if constraint.Check(version) {
+		return resolvedPkg, nil
+	}
+
+	// A version that the app requests takes precedence over the constraint
+	if pkg.Source != DefaultSource && pkg.Source != PreviousVersionSource {
+		log.Warnf("%s %s from %s does not satisfy %s from %s", pkg.Name, version, pkg.Source, pkg.Constraint, pkg.ConstraintSource)
+		return resolvedPkg, nil
+	}
+
+	version, err = r.bestVersion(pkg, constraint, pkg.Constraint, pkg.ConstraintSource)
+	if err != nil {
+		return nil, err
+	}
+	resolvedPkg.ResolvedVersion = &version
+	resolvedPkg.Source = pkg.ConstraintSource
+
+	return resolvedPkg, nil
+}
+
+// resolveVersion resolves the requested version of a package. It also returns the normalized range of the version
+func (r *Resolver) resolveVersion(pkg *RequestedPackage) (string, string, error) {
+	constraint, err := ParseConstraint(pkg.Version, pkg.ConstraintStyle)
+	if err == nil && !constraint.IsPlain() {
+		if !constraint.HasUpperBound() {
+			if version, ok := r.defaultVersionInRange(pkg, constraint); ok {
+				return version, constraint.String(), nil
 			}
-		} else {
-			// Otherwise, we just get the latest version
-			var err error
-			latestVersion, err = r.mise.GetLatestVersion(name, fuzzyVersion)
-			if err != nil {
-				return nil, err
+		}
+
+		version, err := r.bestVersion(pkg, constraint, pkg.Version, pkg.Source)
+		if err != nil {
+			return "", "", err
+		}
+		return version, constraint.String(), nil
+	}
+
+	fuzzyVersion := resolveToFuzzyVersion(pkg.Version)
+
+	// If there is a custom version validator, we get possible versions and pick the latest one that matches
+	if pkg.IsVersionAvailable != nil {
+		versions, err := r.versions.AllVersions(pkg.Name, fuzzyVersion)
+		if err != nil {
+			return "", "", err
+		}
+
+		for i := len(versions) - 1; i >= 0; i-- {
+			if pkg.IsVersionAvailable(versions[i]) {
+				return versions[i], "", nil
 			}
 		}
 
-		log.Debugf("Resolved package version %s %s to %s from %s", name, pkg.Version, latestVersion, pkg.Source)
+		return "", "", fmt.Errorf("no version available for %s %s", pkg.Name, pkg.Version)
+	}
+
+	// Otherwise, we just get the latest version
+	latestVersion, err := r.versions.LatestVersion(pkg.Name, fuzzyVersion)
+	if err != nil {
+		return "", "", err
+	}
+
+	return latestVersion, "", nil
+}
+
+// bestVersion returns the highest available version that satisfies a range
+func (r *Resolver) bestVersion(pkg *RequestedPackage, constraint *Constraint, requested, source string) (string, error) {
+	versions, err := r.versions.AllVersions(pkg.Name, "latest")
+	if err != nil {
+		return "", err
+	}
+
+	// Availability is checked from the best match down, since checking a version can be slow
+	versions = slices.Clone(versions)
+	version, ok := constraint.Best(versions)
+	for ok && pkg.IsVersionAvailable != nil && !pkg.IsVersionAvailable(version) {
+		versions = slices.DeleteFunc(versions, func(v string) bool { return v == version })
+		version, ok = constraint.Best(versions)
+	}
+
+	if !ok {
+		return "", fmt.Errorf("no version available for %s %s (%s) from %s", pkg.Name, requested, constraint, source)
+	}
+
+	return version, nil
+}
+
+// defaultVersionInRange resolves the default version of a package and returns it if it satisfies the constraint.
+// This keeps open ranges like `>=18` on the tested default instead of the newest release
+func (r *Resolver) defaultVersionInRange(pkg *RequestedPackage, constraint *Constraint) (string, bool) {
+	if len(pkg.Requests) == 0 || pkg.Requests[0].Source != DefaultSource {
+		return "", false
+	}
+
+	// Defaults are versions like `22` or `lts`, but not ranges
+	if defaultConstraint, err := ParseConstraint(pkg.Requests[0].Version, pkg.ConstraintStyle); err == nil && !defaultConstraint.IsPlain() {
+		return "", false
+	}
+
+	version, err := r.versions.LatestVersion(pkg.Name, resolveToFuzzyVersion(pkg.Requests[0].Version))
+	if err != nil || !constraint.Check(version) {
+		return "", false
+	}
+
+	if pkg.IsVersionAvailable != nil && !pkg.IsVersionAvailable(version) {
+		return "", false
+	}
+
+	return version, true
+}
+
+// SetVersionSource changes where the available versions of packages are found
+func (r *Resolver) SetVersionSource(source VersionSource) {
+	r.versions = source
+}
+
+// SetLockedPackages reuses the resolved versions of a lockfile instead of resolving them again.
+// When frozen, resolving fails if the requested packages differ from the locked ones
+func (r *Resolver) SetLockedPackages(lockedPackages map[string]*ResolvedPackage, frozen bool) {
+	r.lockedPackages = lockedPackages
+	r.frozen = frozen
+}
+
+// lockedVersion returns the locked version of a package if it was locked with the same requested version
+func (r *Resolver) lockedVersion(pkg *RequestedPackage) string {
+	locked, ok := r.lockedPackages[pkg.Name]
+	if !ok || locked.RequestedVersion == nil || locked.ResolvedVersion == nil {
+		return ""
+	}
 
-		resolvedPkg := &ResolvedPackage{
-			Name:             name,
-			RequestedVersion: &pkg.Version,
-			ResolvedVersion:  &latestVersion,
-			Source:           pkg.Source,
+	if *locked.RequestedVersion != pkg.Version {
+		return ""
+	}
+
+	if pkg.Constraint != "" {
+		if constraint, err := ParseConstraint(pkg.Constraint, pkg.ConstraintStyle); err == nil && !constraint.Check(*locked.ResolvedVersion) {
+			return ""
 		}
+	}
+
+	return *locked.ResolvedVersion
+}
 
-		resolvedPackages[name] = resolvedPkg
+func (r *Resolver) checkLockDrift() error {
+	if !r.frozen {
+		return nil
 	}
 
-	return resolvedPackages, nil
+	changes := []string{}
+	for _, name := range slices.Sorted(maps.Keys(r.packages)) {
+		pkg := r.packages[name]
+		locked, ok := r.lockedPackages[name]
+
+		switch {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("%s %s is not locked", name, pkg.Version))
+		case r.lockedVersion(pkg) == "":
+			lockedVersion := ""
+			if locked.RequestedVersion != nil {
+				lockedVersion = *locked.RequestedVersion
+			}
+			changes = append(changes, fmt.Sprintf("%s is requested as %s but locked as %s", name, pkg.Version, lockedVersion))
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(r.lockedPackages)) {
+		if _, ok := r.packages[name]; !ok {
+			changes = append(changes, fmt.Sprintf("%s is locked but no longer used", name))
+		}
+	}
+
+	if len(changes) > 0 {
+		return &LockDriftError{Changes: changes}
+	}
+
+	return nil
 }
 
 func (r *Resolver) Get(name string) *RequestedPackage {
@@ -121,12 +390,43 @@ func (r *Resolver) Default(name, defaultVersion string) PackageRef {
 
 	// If there is a previous version of the package, use that instead of the default version
 	if r.previousVersions[name] != "" && r.previousVersions[name] != defaultVersion {
-		r.Version(PackageRef{Name: name}, r.previousVersions[name], "previous installed version")
+		r.Version(PackageRef{Name: name}, r.previousVersions[name], PreviousVersionSource)
 	}
 
+	// Versions that the app requests take precedence over the default and previous versions
+	for _, hint := range r.hints {
+		if hint.Package != name {
+			continue
+		}
+
+		if hint.Constraint {
+			r.Constrain(PackageRef{Name: name}, hint.Version, hint.Source)
+		} else {
+			r.Version(PackageRef{Name: name}, hint.Version, hint.Source)
+		}
+	}
+
+	return PackageRef{Name: name}
+}
+
+// Fixed adds a package that is installed by something other than mise, so it resolves to the fixed version
+// instead of asking mise. It is still part of the resolved packages and the lockfile
+func (r *Resolver) Fixed(name, version, fixedVersion, source string) PackageRef {
+	r.packages[name] = &RequestedPackage{
+		Name:         name,
+		Version:      version,
+		Source:       source,
+		Requests:     []VersionRequest{{Version: version, Source: source}},
+		FixedVersion: fixedVersion,
+	}
 	return PackageRef{Name: name}
 }
 
+// SetVersionHints requests the versions that the app asks for when a package is added. See FindVersionHints
+func (r *Resolver) SetVersionHints(hints []VersionHint) {
+	r.hints = hints
+}
+
 func (r *Resolver) Version(ref PackageRef, version, source string) PackageRef {
 	if pkg, exists := r.packages[ref.Name]; exists {
 		pkg.SetVersion(strings.TrimSpace(version), source)
@@ -134,6 +434,15 @@ func (r *Resolver) Version(ref PackageRef, version, source string) PackageRef {
 	return ref
 }
 
+// Constrain sets a range that the resolved version of a package must satisfy, without requesting a version
+func (r *Resolver) Constrain(ref PackageRef, constraint, source string) PackageRef {
+	if pkg, exists := r.packages[ref.Name]; exists {
+		pkg.Constraint = strings.TrimSpace(constraint)
+		pkg.ConstraintSource = source
+	}
+	return ref
+}
+
 func (r *Resolver) SetPreviousVersion(name, version string) {
 	r.previousVersions[name] = version
 }
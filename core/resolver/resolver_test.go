@@ -1,9 +1,10 @@
 package resolver
 
 import (
+	"sync"
 	"testing"
+	"time"
 
-	"github.com/unbindapp/railpack/core/mise"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -18,10 +19,25 @@ func TestPackagesWithDefaults(t *testing.T) {
 	assert.Equal(t, "package.json engines", pkg.Source)
 }
 
-func TestPackageResolver(t *testing.T) {
-	resolver, err := NewResolver(mise.TestInstallDir)
+// newTestResolver creates a resolver that resolves versions from a static index instead of mise
+func newTestResolver(t *testing.T) *Resolver {
+	resolver, err := NewResolver(t.TempDir())
 	require.NoError(t, err)
 
+	index := NewVersionIndex()
+	index.Set("node", []string{"18.20.4", "22.12.0", "23.4.0", "23.5.0"})
+	index.Set("bun", []string{"1.1.38", "1.2.0"})
+	index.Set("go", []string{"1.21.13", "1.22.10", "1.23.4"})
+	index.Set("python", []string{"3.11.11", "3.12.8", "3.13.1"})
+	index.Set("php", []string{"7.3.26", "7.3.27", "7.3.33", "8.4.2"})
+	resolver.SetVersionSource(index)
+
+	return resolver
+}
+
+func TestPackageResolver(t *testing.T) {
+	resolver := newTestResolver(t)
+
 	// Set up Node.js
 	node := resolver.Default("node", "18")
 	resolver.Version(node, "23", "package.json engines")
@@ -80,8 +96,7 @@ func TestPackageResolver(t *testing.T) {
 }
 
 func TestPackageResolverWithPreviousVersions(t *testing.T) {
-	resolver, err := NewResolver(mise.TestInstallDir)
-	require.NoError(t, err)
+	resolver := newTestResolver(t)
 
 	resolver.SetPreviousVersion("node", "16")
 
@@ -105,14 +120,180 @@ func TestPackageResolverWithPreviousVersions(t *testing.T) {
 }
 
 func TestResolvingPackagesNotAvailable(t *testing.T) {
-	resolver, err := NewResolver(mise.TestInstallDir)
-	require.NoError(t, err)
+	resolver := newTestResolver(t)
 
 	node := resolver.Default("node", "18.20")
 	resolver.SetVersionAvailable(node, func(version string) bool {
 		return version == "100"
 	})
 
+	_, err := resolver.ResolvePackages()
+	require.Error(t, err)
+}
+
+func TestLockedPackages(t *testing.T) {
+	locked := func(name, requested, version string) *ResolvedPackage {
+		return &ResolvedPackage{Name: name, RequestedVersion: &requested, ResolvedVersion: &version}
+	}
+
+	newResolver := func() *Resolver {
+		// Every package is locked, so mise is never used
+		return &Resolver{packages: map[string]*RequestedPackage{}, previousVersions: map[string]string{}}
+	}
+
+	resolver := newResolver()
+	resolver.SetLockedPackages(map[string]*ResolvedPackage{
+		"node": locked("node", "22", "22.3.0"),
+	}, true)
+	resolver.Version(resolver.Default("node", "20"), "22", "package.json engines")
+
+	resolvedPackages, err := resolver.ResolvePackages()
+	require.NoError(t, err)
+	assert.Equal(t, "22.3.0", *resolvedPackages["node"].ResolvedVersion)
+	assert.Equal(t, "package.json engines", resolvedPackages["node"].Source)
+
+	resolver = newResolver()
+	resolver.SetLockedPackages(map[string]*ResolvedPackage{
+		"node":   locked("node", "22", "22.3.0"),
+		"python": locked("python", "3.12", "3.12.1"),
+	}, true)
+	resolver.Default("node", "20")
+	resolver.Default("bun", "latest")
+
+	_, err = resolver.ResolvePackages()
+	var driftErr *LockDriftError
+	require.ErrorAs(t, err, &driftErr)
+	assert.Equal(t, []string{
+		"bun latest is not locked",
+		"node is requested as 20 but locked as 22",
+		"python is locked but no longer used",
+	}, driftErr.Changes)
+}
+
+func TestResolveConstraints(t *testing.T) {
+	resolver := newTestResolver(t)
+
+	node := resolver.Default("node", "22")
+	resolver.Version(node, ">=18 <23", "package.json > engines > node")
+
+	python := resolver.Default("python", "3.13")
+	resolver.Version(python, ">=3.11,<3.13", "pyproject.toml > project > requires-python")
+
+	php := resolver.Default("php", "8.4")
+	resolver.Version(php, "^7.3", "composer.json > require > php")
+	resolver.SetVersionAvailable(php, func(version string) bool {
+		return version != "7.3.33"
+	})
+
+	resolvedPackages, err := resolver.ResolvePackages()
+	require.NoError(t, err)
+
+	nodeResolved := resolvedPackages["node"]
+	assert.Equal(t, "22.12.0", *nodeResolved.ResolvedVersion)
+	assert.Equal(t, ">=18 <23", nodeResolved.Constraint)
+	assert.Equal(t, []VersionRequest{
+		{Version: "22", Source: DefaultSource},
+		{Version: ">=18 <23", Source: "package.json > engines > node"},
+	}, nodeResolved.Requests)
+
+	assert.Equal(t, "3.12.8", *resolvedPackages["python"].ResolvedVersion)
+	assert.Equal(t, "7.3.27", *resolvedPackages["php"].ResolvedVersion)
+
+	// Ranges without an upper bound keep the default version if it satisfies them
+	resolver = newTestResolver(t)
+	node = resolver.Default("node", "22")
+	resolver.Version(node, ">=18", "package.json > engines > node")
+	python = resolver.Default("python", "3.11")
+	resolver.Version(python, ">=3.12", "pyproject.toml > project > requires-python")
+	php = resolver.Default("php", "8.4")
+	resolver.Version(php, ">=7.3", "composer.json > require > php")
+	resolver.SetVersionAvailable(php, func(version string) bool {
+		return version != "8.4.2"
+	})
+
+	resolvedPackages, err = resolver.ResolvePackages()
+	require.NoError(t, err)
+	assert.Equal(t, "22.12.0", *resolvedPackages["node"].ResolvedVersion)
+	assert.Equal(t, "3.13.1", *resolvedPackages["python"].ResolvedVersion)
+	assert.Equal(t, "7.3.33", *resolvedPackages["php"].ResolvedVersion)
+
+	resolver = newTestResolver(t)
+	node = resolver.Default("node", "22")
+	resolver.Version(node, "^19", "package.json > engines > node")
+
 	_, err = resolver.ResolvePackages()
 	require.Error(t, err)
 }
+
+// slowSource records how many versions are resolved at the same time
+type slowSource struct {
+	index    *VersionIndex
+	mu       sync.Mutex
+	inFlight int
+	max      int
+}
+
+func (s *slowSource) track() func() {
+	s.mu.Lock()
+	s.inFlight++
+	s.max = max(s.max, s.inFlight)
+	s.mu.Unlock()
+
+	time.Sleep(50 * time.Millisecond)
+
+	return func() {
+		s.mu.Lock()
+		s.inFlight--
+		s.mu.Unlock()
+	}
+}
+
+func (s *slowSource) LatestVersion(pkg, version string) (string, error) {
+	defer s.track()()
+	return s.index.LatestVersion(pkg, version)
+}
+
+func (s *slowSource) AllVersions(pkg, version string) ([]string, error) {
+	defer s.track()()
+	return s.index.AllVersions(pkg, version)
+}
+
+func TestResolvePackagesConcurrently(t *testing.T) {
+	resolver := newTestResolver(t)
+	source := &slowSource{index: resolver.versions.(*VersionIndex)}
+	resolver.SetVersionSource(source)
+
+	resolver.Default("node", "22")
+	resolver.Default("python", "3.13")
+	resolver.Default("go", "1.23")
+	missing := resolver.Default("bun", "2")
+	resolver.Version(missing, "3", "package.json > packageManager")
+
+	_, err := resolver.ResolvePackages()
+	require.ErrorContains(t, err, "bun")
+	assert.Greater(t, source.max, 1)
+
+	resolver.Version(missing, "1", "package.json > packageManager")
+	resolvedPackages, err := resolver.ResolvePackages()
+	require.NoError(t, err)
+	assert.Equal(t, "22.12.0", *resolvedPackages["node"].ResolvedVersion)
+	assert.Equal(t, "1.2.0", *resolvedPackages["bun"].ResolvedVersion)
+}
+
+func TestResolveFixedPackages(t *testing.T) {
+	resolver := newTestResolver(t)
+	resolver.Default("node", "22")
+	resolver.Fixed("apt:ffmpeg", "latest", "latest", "custom config")
+	resolver.Fixed("url:mytool", "https://example.com/mytool#sha256=abc", "sha256:abc", "custom config")
+
+	resolvedPackages, err := resolver.ResolvePackages()
+	require.NoError(t, err)
+
+	ffmpeg := resolvedPackages["apt:ffmpeg"]
+	require.NotNil(t, ffmpeg)
+	require.Equal(t, "latest", *ffmpeg.ResolvedVersion)
+	require.Equal(t, "custom config", ffmpeg.Source)
+	require.Equal(t, []VersionRequest{{Version: "latest", Source: "custom config"}}, ffmpeg.Requests)
+	require.Equal(t, "sha256:abc", *resolvedPackages["url:mytool"].ResolvedVersion)
+	require.Equal(t, "22.12.0", *resolvedPackages["node"].ResolvedVersion)
+}
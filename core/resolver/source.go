@@ -0,0 +1,327 @@
+package resolver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/unbindapp/railpack/core/mise"
+)
+
+const (
+	// MiseSourceName selects mise as a version source
+	MiseSourceName = "mise"
+
+	httpSourceTimeout = 30 * time.Second
+)
+
+// ErrPackageNotFound is returned by a version source that does not know a package
+var ErrPackageNotFound = errors.New("package not found")
+
+// VersionSource lists the available versions of packages
+type VersionSource interface {
+	// LatestVersion gets the latest version of a package matching the version
+	LatestVersion(pkg, version string) (string, error)
+
+	// AllVersions gets all versions of a package matching the version, from oldest to newest
+	AllVersions(pkg, version string) ([]string, error)
+}
+
+// miseSource resolves versions with mise. Mise is only installed once a version is resolved.
+// When caching is enabled, the versions of each tool are listed once and numeric versions are resolved from the
+// cache. Aliases like `lts` are always resolved by mise
+type miseSource struct {
+	dir   string
+	cache *versionCache
+	once  sync.Once
+	mise  *mise.Mise
+	err   error
+}
+
+// NewMiseSource creates a version source that uses the mise installed in dir
+func NewMiseSource(dir string, cacheOptions VersionCacheOptions) VersionSource {
+	return &miseSource{
+		dir:   dir,
+		cache: newVersionCache(dir, cacheOptions),
+	}
+}
+
+func (s *miseSource) get() (*mise.Mise, error) {
+	s.once.Do(func() {
+		s.mise, s.err = mise.New(s.dir)
+	})
+	return s.mise, s.err
+}
+
+// numericVersionRegex matches the versions that are resolved from the cached list of versions. Other versions,
+// such as `lts` or `lts/iron`, are aliases that only mise knows about
+var numericVersionRegex = regexp.MustCompile(`^[vV]?\d+(\.\d+)*$`)
+
+// usesCache checks if the version is resolved from the cached list of versions instead of by mise
+func (s *miseSource) usesCache(version string) bool {
+	if s.cache == nil {
+		return false
+	}
+
+	version = strings.TrimSpace(version)
+	return version == "" || version == "latest" || numericVersionRegex.MatchString(version)
+}
+
+func (s *miseSource) LatestVersion(pkg, version string) (string, error) {
+	if s.usesCache(version) {
+		index, err := s.cachedVersions(pkg)
+		if err != nil {
+			return "", err
+		}
+
+		latest, err := index.LatestVersion(pkg, version)
+		if err != nil {
+			return "", fmt.Errorf(mise.ErrMiseGetLatestVersion, version, pkg)
+		}
+		return latest, nil
+	}
+
+	m, err := s.get()
+	if err != nil {
+		return "", err
+	}
+	return m.LatestVersion(pkg, version)
+}
+
+func (s *miseSource) AllVersions(pkg, version string) ([]string, error) {
+	if s.usesCache(version) {
+		index, err := s.cachedVersions(pkg)
+		if err != nil {
+			return nil, err
+		}
+
+		versions, err := index.AllVersions(pkg, version)
+		if err != nil {
+			return nil, fmt.Errorf(mise.ErrMiseGetLatestVersion, version, pkg)
+		}
+		return versions, nil
+	}
+
+	m, err := s.get()
+	if err != nil {
+		return nil, err
+	}
+	return m.AllVersions(pkg, version)
+}
+
+// cachedVersions gets all versions of a tool from the cache, or lists them with mise and caches them
+func (s *miseSource) cachedVersions(pkg string) (*VersionIndex, error) {
+	index := NewVersionIndex()
+
+	if versions, ok := s.cache.get(pkg); ok {
+		log.Debugf("Using cached versions of %s", pkg)
+		index.Set(pkg, versions)
+		return index, nil
+	}
+
+	m, err := s.get()
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := m.ListVersions(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("mise did not list any versions of %s", pkg)
+	}
+
+	// Failing to cache only makes the next plan slower
+	if err := s.cache.put(pkg, versions); err != nil {
+		log.Warnf("Failed to cache versions of %s: %v", pkg, err)
+	}
+
+	index.Set(pkg, versions)
+	return index, nil
+}
+
+// HTTPSource resolves versions from an index server.
+// The versions of a tool are a JSON array at `<url>/<tool>`, from oldest to newest. Unknown tools return a 404
+type HTTPSource struct {
+	url    string
+	client *http.Client
+
+	mu    sync.Mutex
+	tools map[string]*VersionIndex
+}
+
+func NewHTTPSource(url string) *HTTPSource {
+	return &HTTPSource{
+		url:    strings.TrimSuffix(url, "/"),
+		client: &http.Client{Timeout: httpSourceTimeout},
+		tools:  map[string]*VersionIndex{},
+	}
+}
+
+func (s *HTTPSource) LatestVersion(pkg, version string) (string, error) {
+	index, err := s.fetch(pkg)
+	if err != nil {
+		return "", err
+	}
+	return index.LatestVersion(pkg, version)
+}
+
+func (s *HTTPSource) AllVersions(pkg, version string) ([]string, error) {
+	index, err := s.fetch(pkg)
+	if err != nil {
+		return nil, err
+	}
+	return index.AllVersions(pkg, version)
+}
+
+// fetch gets the versions of a tool from the server. Versions are only fetched once.
+// Each tool has its own index, so that an index is never changed while it is read
+func (s *HTTPSource) fetch(pkg string) (*VersionIndex, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if index, ok := s.tools[pkg]; ok {
+		return index, nil
+	}
+
+	toolURL := fmt.Sprintf("%s/%s", s.url, url.PathEscape(pkg))
+	resp, err := s.client.Get(toolURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get versions of %s: %w", pkg, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: `%s` is not in the version index at %s", ErrPackageNotFound, pkg, s.url)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get versions of %s from %s: %s", pkg, toolURL, resp.Status)
+	}
+
+	var versions []string
+	if err := json.NewDecoder(resp.Body).Decode(&versions); err != nil {
+		return nil, fmt.Errorf("invalid versions of %s from %s: %w", pkg, toolURL, err)
+	}
+
+	index := NewVersionIndex()
+	index.Set(pkg, versions)
+	s.tools[pkg] = index
+	return index, nil
+}
+
+// ChainSource tries each source in order until one resolves the version.
+// Packages can be routed to a single source, which is used without any fallback
+type ChainSource struct {
+	sources []VersionSource
+	routes  map[string]VersionSource
+}
+
+func NewChainSource(sources ...VersionSource) *ChainSource {
+	return &ChainSource{
+		sources: sources,
+		routes:  map[string]VersionSource{},
+	}
+}
+
+// Route resolves the versions of the package only with the source
+func (c *ChainSource) Route(pkg string, source VersionSource) {
+	c.routes[pkg] = source
+}
+
+func (c *ChainSource) LatestVersion(pkg, version string) (string, error) {
+	return chainResolve(c, pkg, func(source VersionSource) (string, error) {
+		return source.LatestVersion(pkg, version)
+	})
+}
+
+func (c *ChainSource) AllVersions(pkg, version string) ([]string, error) {
+	return chainResolve(c, pkg, func(source VersionSource) ([]string, error) {
+		return source.AllVersions(pkg, version)
+	})
+}
+
+func chainResolve[T any](c *ChainSource, pkg string, resolve func(source VersionSource) (T, error)) (T, error) {
+	if source, ok := c.routes[pkg]; ok {
+		return resolve(source)
+	}
+
+	var zero T
+	if len(c.sources) == 0 {
+		return zero, fmt.Errorf("%w: no version source for `%s`", ErrPackageNotFound, pkg)
+	}
+
+	errs := []error{}
+	for _, source := range c.sources {
+		result, err := resolve(source)
+		if err == nil {
+			return result, nil
+		}
+		errs = append(errs, err)
+	}
+
+	return zero, errors.Join(errs...)
+}
+
+// ParseVersionSources creates a version source from specs. A spec is `mise`, an http(s) URL of an index server,
+// or the path of a version index file. Specs are tried in order, and a `<package>=<spec>` spec routes a package
+// to a single source. Each value can contain multiple specs separated by commas. Mise sources use the mise in miseDir
+func ParseVersionSources(values []string, miseDir string, cacheOptions VersionCacheOptions) (VersionSource, error) {
+	chain := NewChainSource()
+
+	specs := []string{}
+	for _, value := range values {
+		specs = append(specs, strings.Split(value, ",")...)
+	}
+
+	for _, spec := range specs {
+		pkg, sourceSpec, routed := strings.Cut(spec, "=")
+		if !routed {
+			sourceSpec = spec
+		}
+
+		source, err := parseVersionSource(strings.TrimSpace(sourceSpec), miseDir, cacheOptions)
+		if err != nil {
+			return nil, err
+		}
+
+		if routed {
+			chain.Route(strings.TrimSpace(pkg), source)
+		} else {
+			chain.sources = append(chain.sources, source)
+		}
+	}
+
+	// Packages that are not routed still need a source
+	if len(chain.sources) == 0 {
+		chain.sources = append(chain.sources, NewMiseSource(miseDir, cacheOptions))
+	}
+
+	if len(chain.sources) == 1 && len(chain.routes) == 0 {
+		return chain.sources[0], nil
+	}
+
+	return chain, nil
+}
+
+func parseVersionSource(spec, miseDir string, cacheOptions VersionCacheOptions) (VersionSource, error) {
+	switch {
+	case spec == "":
+		return nil, errors.New("version source must not be empty")
+	case spec == MiseSourceName:
+		return NewMiseSource(miseDir, cacheOptions), nil
+	case strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://"):
+		return NewHTTPSource(spec), nil
+	default:
+		return ReadVersionIndex(spec)
+	}
+}
@@ -0,0 +1,215 @@
+package resolver
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/unbindapp/railpack/core/mise"
+)
+
+// staticSource resolves every package to the same version
+type staticSource struct {
+	version string
+	err     error
+}
+
+func (s *staticSource) LatestVersion(pkg, version string) (string, error) {
+	return s.version, s.err
+}
+
+func (s *staticSource) AllVersions(pkg, version string) ([]string, error) {
+	return []string{s.version}, s.err
+}
+
+func TestHTTPSource(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		switch r.URL.Path {
+		case "/versions/node":
+			_, _ = w.Write([]byte(`["22.9.0", "20.18.0", "22.10.0"]`))
+		case "/versions/broken":
+			_, _ = w.Write([]byte(`{`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	source := NewHTTPSource(server.URL + "/versions/")
+
+	latest, err := source.LatestVersion("node", "22")
+	require.NoError(t, err)
+	assert.Equal(t, "22.10.0", latest)
+
+	versions, err := source.AllVersions("node", "latest")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"20.18.0", "22.9.0", "22.10.0"}, versions)
+	assert.Equal(t, int32(1), requests.Load(), "versions are only fetched once")
+
+	_, err = source.LatestVersion("python", "3")
+	require.ErrorIs(t, err, ErrPackageNotFound)
+
+	_, err = source.LatestVersion("broken", "1")
+	require.Error(t, err)
+}
+
+func TestChainSource(t *testing.T) {
+	index := NewVersionIndex()
+	index.Set("node", []string{"22.3.0"})
+
+	failing := &staticSource{err: errors.New("offline")}
+	chain := NewChainSource(failing, index, &staticSource{version: "1.0.0"})
+
+	// Falls back to the next source until one resolves the version
+	latest, err := chain.LatestVersion("node", "22")
+	require.NoError(t, err)
+	assert.Equal(t, "22.3.0", latest)
+
+	latest, err = chain.LatestVersion("bun", "1")
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", latest)
+
+	// Routed packages only use their source
+	chain.Route("node", failing)
+	_, err = chain.AllVersions("node", "22")
+	require.ErrorContains(t, err, "offline")
+
+	_, err = NewChainSource().LatestVersion("node", "22")
+	require.ErrorIs(t, err, ErrPackageNotFound)
+}
+
+func TestParseVersionSources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "versions.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 1, "tools": {"node": ["22.3.0"]}}`), 0644))
+
+	source, err := ParseVersionSources([]string{path}, t.TempDir(), VersionCacheOptions{})
+	require.NoError(t, err)
+	require.IsType(t, &VersionIndex{}, source)
+
+	source, err = ParseVersionSources([]string{"https://versions.example.com"}, t.TempDir(), VersionCacheOptions{})
+	require.NoError(t, err)
+	require.IsType(t, &HTTPSource{}, source)
+
+	source, err = ParseVersionSources([]string{"node=" + path}, t.TempDir(), VersionCacheOptions{})
+	require.NoError(t, err)
+	chain := source.(*ChainSource)
+	require.Len(t, chain.sources, 1)
+	require.IsType(t, &miseSource{}, chain.sources[0])
+	require.IsType(t, &VersionIndex{}, chain.routes["node"])
+
+	source, err = ParseVersionSources([]string{path, "mise"}, t.TempDir(), VersionCacheOptions{})
+	require.NoError(t, err)
+	require.Len(t, source.(*ChainSource).sources, 2)
+
+	source, err = ParseVersionSources([]string{path + ",mise"}, t.TempDir(), VersionCacheOptions{})
+	require.NoError(t, err)
+	require.Len(t, source.(*ChainSource).sources, 2)
+
+	_, err = ParseVersionSources([]string{"missing.json"}, t.TempDir(), VersionCacheOptions{})
+	require.Error(t, err)
+
+	_, err = ParseVersionSources([]string{""}, t.TempDir(), VersionCacheOptions{})
+	require.Error(t, err)
+}
+
+func TestMiseSourceCache(t *testing.T) {
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+
+	// A fake mise that lists versions and records each call
+	bin := filepath.Join(dir, "mise")
+	script := "#!/bin/sh\necho \"$@\" >> " + calls + "\nprintf '22.3.0\\n22.9.0\\n23.0.0-rc.1\\n'\n"
+	require.NoError(t, os.WriteFile(bin, []byte(script), 0755))
+	t.Setenv(mise.BinEnvVar, bin)
+
+	countCalls := func() int {
+		data, err := os.ReadFile(calls)
+		if errors.Is(err, os.ErrNotExist) {
+			return 0
+		}
+		require.NoError(t, err)
+		return strings.Count(string(data), "\n")
+	}
+
+	cacheDir := filepath.Join(dir, "cache")
+	options := VersionCacheOptions{TTL: time.Hour}
+
+	latest, err := NewMiseSource(cacheDir, options).LatestVersion("node", "22")
+	require.NoError(t, err)
+	assert.Equal(t, "22.9.0", latest)
+	assert.Equal(t, 1, countCalls())
+
+	// Another plan reuses the cached versions
+	versions, err := NewMiseSource(cacheDir, options).AllVersions("node", "latest")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"22.3.0", "22.9.0", "23.0.0-rc.1"}, versions)
+	assert.Equal(t, 1, countCalls())
+
+	_, err = NewMiseSource(cacheDir, options).LatestVersion("node", "18")
+	require.Error(t, err)
+	assert.Equal(t, 1, countCalls())
+
+	// Refreshing lists the versions again, once
+	refreshed := NewMiseSource(cacheDir, VersionCacheOptions{TTL: time.Hour, Refresh: true})
+	_, err = refreshed.LatestVersion("node", "22")
+	require.NoError(t, err)
+	_, err = refreshed.LatestVersion("node", "22")
+	require.NoError(t, err)
+	assert.Equal(t, 2, countCalls())
+
+	// Expired versions are listed again
+	_, err = NewMiseSource(cacheDir, VersionCacheOptions{TTL: time.Nanosecond}).LatestVersion("node", "22")
+	require.NoError(t, err)
+	assert.Equal(t, 3, countCalls())
+}
+
+func TestMiseSourceCacheAliases(t *testing.T) {
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+
+	// A fake mise that resolves `latest` queries to the LTS line and lists versions with prereleases
+	bin := filepath.Join(dir, "mise")
+	script := "#!/bin/sh\necho \"$@\" >> " + calls + "\ncase \"$1\" in\n" +
+		"latest) echo 20.18.1 ;;\n" +
+		"*) case \"$2\" in *@*) printf '20.18.0\\n20.18.1\\n' ;; *) printf '20.18.1\\n22.9.0\\n22.10.0-rc.1\\n23.0.0-beta.1\\n' ;; esac ;;\n" +
+		"esac\n"
+	require.NoError(t, os.WriteFile(bin, []byte(script), 0755))
+	t.Setenv(mise.BinEnvVar, bin)
+
+	source := NewMiseSource(filepath.Join(dir, "cache"), VersionCacheOptions{TTL: time.Hour})
+
+	// Aliases are resolved by mise instead of matching every cached version
+	for _, version := range []string{"lts", "lts/iron"} {
+		latest, err := source.LatestVersion("node", version)
+		require.NoError(t, err)
+		assert.Equal(t, "20.18.1", latest, version)
+	}
+
+	versions, err := source.AllVersions("node", "lts")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"20.18.0", "20.18.1"}, versions)
+
+	// Prereleases in the cached versions are skipped for the latest version
+	latest, err := source.LatestVersion("node", "22")
+	require.NoError(t, err)
+	assert.Equal(t, "22.9.0", latest)
+
+	latest, err = source.LatestVersion("node", "latest")
+	require.NoError(t, err)
+	assert.Equal(t, "22.9.0", latest)
+
+	data, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	assert.Equal(t, "latest node@lts\nlatest node@lts/iron\nls-remote node@lts\nls-remote node\n", string(data))
+}
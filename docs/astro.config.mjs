@@ -58,6 +58,10 @@ export default defineConfig({
               label: "Running Railpack in Production",
               link: "/guides/running-railpack-in-production",
             },
+            {
+              label: "Provider Plugins",
+              link: "/guides/provider-plugins",
+            },
           ],
         },
         {